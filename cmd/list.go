@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mirceanton/homer-sync/internal/controller"
+	"github.com/mirceanton/homer-sync/internal/k8s"
+)
+
+// newListCmd returns the list subcommand, which prints the items the current
+// configuration puts on the dashboard, including hidden ones, without
+// writing anything.
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the dashboard items the current configuration produces, including hidden ones",
+		Args:  cobra.NoArgs,
+		// Scan failures are about the cluster, not the invocation.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := buildConfig()
+			if err != nil {
+				return err
+			}
+			setupLogging(cfg.LogLevel, cfg.LogFormat)
+
+			clients, err := k8s.NewClients(clientOptions(cfg))
+			if err != nil {
+				return fmt.Errorf("initialise kubernetes clients: %w", err)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+			return controller.New(clients, cfg).List(ctx, cmd.OutOrStdout())
+		},
+	}
+}
@@ -4,16 +4,21 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/mirceanton/homer-sync/internal/config"
 	"github.com/mirceanton/homer-sync/internal/controller"
+	"github.com/mirceanton/homer-sync/internal/health"
 	"github.com/mirceanton/homer-sync/internal/k8s"
 )
 
@@ -29,31 +34,217 @@ func newRootCmd() *cobra.Command {
 		Short: "Automatically generate a Homer dashboard config from Kubernetes HTTPRoutes",
 		RunE:  runE,
 	}
+	cmd.AddCommand(newValidateTemplateCmd(), newPreflightCmd(), newListCmd())
 
-	// Flags – names mirror the env-var suffix (HOMER_SYNC_<FLAG>).
-	f := cmd.Flags()
-	f.StringSlice("gateway-names", nil,
+	// Flags – names mirror the env-var suffix (HOMER_SYNC_<FLAG>). They are
+	// persistent so subcommands such as preflight see the same configuration.
+	// Defaults come from config.Default, shared with embedders of homersync.
+	def := config.Default()
+	f := cmd.PersistentFlags()
+	f.String("config", "",
+		"Path to a YAML or JSON file setting options by flag name; flags and env vars take precedence")
+	f.String("kubeconfig", def.Kubeconfig,
+		"Kubeconfig of the cluster to scan; when set it is used even inside a pod (default: in-cluster config, then $KUBECONFIG or ~/.kube/config)")
+	f.String("context", def.KubeContext,
+		"Kubeconfig context of the cluster to scan (default: the current context)")
+	f.StringSlice("sources", def.Sources,
+		"Comma-separated resource kinds to scan: httproute, ingress, openshift-route")
+	f.StringSlice("route-kinds", def.RouteKinds,
+		"Comma-separated additional Gateway API route kinds to scan: grpcroute, tcproute")
+	f.String("route-label-selector", def.RouteLabelSelector,
+		"Label selector applied server-side when listing routes (e.g. dashboard=true)")
+	f.StringSlice("namespace-include", def.NamespaceInclude,
+		"Comma-separated namespaces (exact names or globs) to scan exclusively")
+	f.StringSlice("namespace-exclude", def.NamespaceExclude,
+		"Comma-separated namespaces (exact names or globs) to skip; ignored when namespace-include is set")
+	f.String("filter-mode", "",
+		"How annotations and filters combine: opt-in, opt-out or strict (default: opt-out when a filter is set, else opt-in)")
+	f.StringSlice("gateway-names", def.GatewayNames,
 		"Comma-separated gateway names to filter HTTPRoutes by (opt-out mode when set)")
-	f.StringSlice("domain-suffixes", nil,
-		"Comma-separated domain suffixes to filter hostnames by (e.g. .home.example.com)")
-	f.String("configmap-name", "homer-config",
+	f.StringSlice("gateway-sections", def.GatewaySections,
+		"Comma-separated listener section names a route's parentRef must attach to (e.g. https)")
+	f.StringSlice("domain-suffixes", def.DomainSuffixes,
+		"Comma-separated domain suffixes or globs to filter hostnames by (e.g. .home.example.com, *.example.com)")
+	f.StringSlice("domain-group-map", nil,
+		"Comma-separated suffix=group rules grouping routes by the linked hostname (e.g. .internal.example.com=Internal,.example.com=Public)")
+	f.StringSlice("exclude-hostnames", def.ExcludeHostnames,
+		"Comma-separated hostnames or globs never used for links (e.g. *.internal.example.com)")
+	f.StringSlice("prefer-hostname-suffix", def.PreferSuffixes,
+		"Comma-separated hostname suffixes or globs, in priority order, selecting which route hostname is linked")
+	f.Bool("prefer-shortest", def.PreferShortest,
+		"Link the shortest hostname among the candidates instead of the first")
+	f.Bool("read-backend-annotations", def.BackendAnnotations,
+		"Merge home.mirceanton.com/* annotations from each route's backend Services (route annotations win)")
+	f.StringSlice("subtitle-fallback", def.SubtitleFallback,
+		"Comma-separated annotation or label keys, in order, whose value is the subtitle when home.mirceanton.com/subtitle is unset")
+	f.String("apply-mode", def.ApplyMode,
+		"How the output object is written: update (get, then merge-patch our data key) or ssa (server-side apply)")
+	f.Bool("require-accepted", def.RequireAccepted,
+		"Skip Gateway API routes that no parent reports as Accepted=True")
+	f.String("output-kind", def.OutputKind,
+		"Kind of object the Homer config is written to: configmap or secret")
+	f.String("configmap-name", def.ConfigMapName,
 		"Name of the ConfigMap to write the Homer config into")
+	f.Bool("auto-icon", def.AutoIcon,
+		"Derive a logo URL from the service name when no icon annotation is set")
+	f.String("icon-base-url", def.IconBaseURL,
+		"Base URL of the icon pack used by --auto-icon")
+	f.String("configmap-key", def.ConfigMapKey,
+		"Data key the rendered Homer config is stored under")
+	f.String("output-file", def.OutputFile,
+		"Write the rendered config to this file instead of the cluster (cluster reads still happen)")
+	f.String("output-kubeconfig", def.OutputKubeconfig,
+		"Kubeconfig for the cluster the ConfigMap is written to (default: the cluster routes are read from)")
+	f.String("output-context", def.OutputContext,
+		"Kubeconfig context for the output cluster")
 	f.String("configmap-namespace", "",
 		"Namespace for the ConfigMap (auto-detected from service account when empty)")
-	f.Bool("daemon", true,
+	f.StringSlice("configmap-labels", nil,
+		"Comma-separated key=value labels set on the output ConfigMap or Secret (e.g. app.kubernetes.io/managed-by=homer-sync)")
+	f.StringSlice("configmap-annotations", nil,
+		"Comma-separated key=value annotations set on the output ConfigMap or Secret")
+	f.Bool("compress", def.Compress,
+		"Store the config gzipped under the <configmap-key>.gz binaryData key once it exceeds --compress-threshold")
+	f.Int("compress-threshold", def.CompressThreshold,
+		"Size in bytes above which --compress gzips the config")
+	f.Bool("auto-shard", def.AutoShard,
+		"Split a config too large for one object by group across numbered objects (<configmap-name>-0, -1, ...)")
+	f.Bool("set-owner-reference", def.SetOwnerReference,
+		"Set an owner reference to the homer-sync Deployment on created ConfigMaps")
+	f.String("configmap-map", "",
+		"Extra ConfigMaps receiving only some groups, e.g. internal=Infra,Media;guest=Public")
+	f.Bool("daemon", def.Daemon,
 		"Run continuously; set to false to exit after one sync")
-	f.Int("scan-interval", 300,
+	f.Bool("fail-on-initial-sync", def.FailOnInitialSync,
+		"In daemon mode, exit with an error when the preflight check or first scan fails instead of retrying")
+	f.Bool("sync-on-shutdown", def.SyncOnShutdown,
+		"In daemon mode, run one final sync on SIGTERM/SIGINT before exiting")
+	f.Int("shutdown-timeout", def.ShutdownTimeout,
+		"Seconds allowed for the final sync on shutdown (0 = no limit)")
+	f.Bool("force-sync", def.ForceSync,
+		"Write the output on every scan even when its content is unchanged, correcting hand edits")
+	f.Bool("dry-run", def.DryRun,
+		"Print the rendered config and a diff against the current one instead of writing it")
+	f.Bool("emit-events", def.EmitEvents,
+		"Emit Kubernetes Events on the output ConfigMap for sync actions and render failures")
+	f.Int("scan-interval", def.ScanInterval,
 		"Seconds between scans in daemon mode")
-	f.String("log-level", "info",
+	f.Int("namespace-cache-ttl", -1,
+		"Seconds the namespace list is reused between scans (-1 = --scan-interval, 0 = list every scan)")
+	f.Int("workers", def.Workers,
+		"Goroutines resolving routes into services in parallel (0 = GOMAXPROCS)")
+	f.Int("api-timeout", def.APITimeout,
+		"Seconds allowed for each Kubernetes API call (0 disables)")
+	f.Int("api-retries", def.APIRetries,
+		"Retries for List calls failing with a transient API error (timeouts, throttling)")
+	f.Int("once-timeout", def.OnceTimeout,
+		"Overall deadline in seconds for a one-shot run, including retries (0 = no deadline)")
+	f.Int("once-retries", def.OnceRetries,
+		"Number of times a failed one-shot run is retried with backoff before giving up")
+	f.String("fail-on", def.FailOn,
+		"One-shot exit policy: render-error (fail on fatal errors), any-skip (also when a route is skipped) or never")
+	f.String("health-addr", def.HealthAddr,
+		"Listen address for the /healthz and /readyz probe server (disabled when empty)")
+	f.Bool("self-exclude", def.SelfExclude,
+		"Skip the controller's own HTTPRoute (detected from POD_NAMESPACE/POD_NAME)")
+	f.String("self-name", "",
+		"Route name --self-exclude skips (default: the Deployment name derived from POD_NAME)")
+	f.String("log-level", strings.ToLower(def.LogLevel.String()),
 		"Log verbosity: debug, info, warn, error")
-	f.String("title", "Home Dashboard",
+	f.String("log-format", def.LogFormat,
+		"Log output format: text or json")
+	f.String("title", def.Title,
 		"Homer dashboard title")
-	f.String("subtitle", "",
+	f.String("subtitle", def.Subtitle,
 		"Homer dashboard subtitle")
-	f.Int("columns", 5,
+	f.Int("columns", def.Columns,
 		"Number of service columns in the Homer layout")
-	f.String("template-path", "",
+	f.String("theme", def.Theme,
+		"Homer theme name; omitted from the config when empty")
+	f.String("colors-file", "",
+		"YAML file with Homer's colors block (light/dark palettes); omitted when empty")
+	f.String("default-group-icon", def.DefaultGroupIcon,
+		"Font Awesome class for groups without a group-icon annotation")
+	f.String("template-path", def.TemplatePath,
 		"Path to a custom Go template file; falls back to the built-in template when empty")
+	f.String("template-dir", def.TemplateDir,
+		"Directory of *.tmpl files composed into one template set; used when --template-path is empty")
+	f.String("template-entry", def.TemplateEntry,
+		"Name of the template executed from --template-dir (file name without .tmpl, or a define'd name)")
+	f.String("homer-schema", def.HomerSchema,
+		"Built-in template to render when no custom template is set: v1 or v2 (newer Homer releases)")
+	f.Bool("no-header", def.NoHeader,
+		"Do not prepend the generated-by comment to the rendered config")
+	f.Bool("canonicalize-yaml", def.CanonicalYAML,
+		"Re-serialise the rendered config with sorted keys so its output is deterministic")
+	f.String("status-configmap", "",
+		"ConfigMap (name or namespace/name) receiving the last scan's status and counts (disabled when empty)")
+	f.String("template-configmap", "",
+		"ConfigMap (name or namespace/name) holding a custom template; used when --template-path and --template-dir are empty")
+	f.String("template-configmap-key", "config.tmpl",
+		"Data key of the template ConfigMap holding the template source")
+	f.String("url-base", def.URLBase,
+		"Base URL that url annotations starting with / are resolved against")
+	f.String("url-rewrite", "",
+		"Semicolon-separated regex=>replacement rules rewriting service links, e.g. ^https://(\\w+)\\.\\w+\\.svc$=>https://$1.apps.example.com")
+	f.String("url-rewrite-mode", "first",
+		"How url-rewrite rules combine: first (only the first matching rule applies) or all (each rule applies in turn)")
+	f.String("default-scheme", def.DefaultScheme,
+		"Scheme for links without a scheme annotation or listener hint: auto (http for IP, localhost, single-label and .local hosts), https or http")
+	f.String("default-target", def.DefaultTarget,
+		"Link target for items without a target annotation: _blank, _self, _parent, _top, or empty to omit it")
+	f.Bool("no-url-normalize", def.NoURLNormalize,
+		"Keep links verbatim instead of lowercasing hosts, dropping default ports and collapsing trailing slashes")
+	f.StringSlice("label-to-tag", nil,
+		"Comma-separated label=value=>tagstyle rules mapping namespace labels to Homer tags")
+	f.Bool("resolve-secrets", def.ResolveSecrets,
+		"Resolve home.mirceanton.com/apikey-secret Secret references into smart card API keys, re-rendering when a referenced Secret changes; requires --output-kind=secret")
+	f.StringSlice("group-order", def.Order.GroupOrder,
+		"Comma-separated group names rendered first, in this order; other groups follow")
+	f.StringSlice("group-order-by", def.Order.GroupKeys,
+		"Comma-separated keys groups are ordered by (sort, name)")
+	f.String("sort-by", "manual",
+		"Primary item order: manual (per --item-order-by) or created (newest route first)")
+	f.StringSlice("item-order-by", def.Order.ItemKeys,
+		"Comma-separated keys items within a group are ordered by (sort, sort-key, name, url, created)")
+	f.Bool("dedupe", def.Dedupe,
+		"Drop services whose URL duplicates an earlier one, e.g. apps mirrored across namespaces")
+	f.String("on-duplicate", def.OnDuplicate,
+		"Handling of duplicate service names within a group: warn, suffix, skip")
+	f.String("group-by", def.GroupBy.Source,
+		"Default group source for each route: namespace, annotation:<key> or label:<key> of its namespace")
+	f.StringSlice("disable-groups", def.DisableGroups,
+		"Comma-separated group names whose routes are all skipped (e.g. Experimental)")
+	f.StringSlice("strip-namespace-prefix", def.StripNSPrefixes,
+		"Comma-separated namespace prefixes removed before naming the group; the removed part becomes the item tag (e.g. prod-,staging-)")
+	f.StringSlice("strip-namespace-suffix", def.StripNSSuffixes,
+		"Comma-separated namespace suffixes removed before naming the group; the removed part becomes the item tag (e.g. -prod,-dev)")
+	f.String("summary-group", "",
+		"Add a summary group of all services (all) or the N most recently created ones (recent:N)")
+	f.String("summary-group-name", "",
+		"Name of the summary group (defaults to \"All Services\" or \"Recently Added\")")
+	f.String("summary-group-icon", "fas fa-star",
+		"Font Awesome class for the summary group icon")
+	f.String("api-proxy-url", def.APIProxyURL,
+		"HTTP proxy for Kubernetes API traffic; overrides HTTPS_PROXY/NO_PROXY when set")
+	f.String("message-content", def.Message.Content,
+		"Content of a static Homer message banner; empty disables it")
+	f.String("message-style", def.Message.Style,
+		"Homer message style for the static banner")
+	f.String("message-title", def.Message.Title,
+		"Title of the static banner")
+	f.String("message-icon", def.Message.Icon,
+		"Font Awesome class for the static banner icon")
+	f.String("maintenance-configmap", "",
+		"ConfigMap (name or namespace/name) whose key, when non-empty, is shown as a maintenance banner")
+	f.String("maintenance-key", "message",
+		"Data key of the maintenance ConfigMap holding the banner content")
+	f.String("maintenance-style", "is-warning",
+		"Homer message style for the maintenance banner")
+	f.String("maintenance-title", "Maintenance",
+		"Title of the maintenance banner")
+	f.String("maintenance-icon", "fas fa-exclamation-triangle",
+		"Font Awesome class for the maintenance banner icon")
 
 	// Bind each flag to its canonical env var, preserving backward compatibility
 	// with the Python-era variable names.
@@ -66,17 +257,108 @@ func newRootCmd() *cobra.Command {
 		}
 	}
 
+	bindEnv("config", "HOMER_SYNC_CONFIG")
+	bindEnv("kubeconfig", "HOMER_SYNC_KUBECONFIG")
+	bindEnv("context", "HOMER_SYNC_CONTEXT")
+	bindEnv("sources", "HOMER_SYNC_SOURCES")
+	bindEnv("route-kinds", "HOMER_SYNC_ROUTE_KINDS")
+	bindEnv("route-label-selector", "HOMER_SYNC_ROUTE_LABEL_SELECTOR")
+	bindEnv("namespace-include", "HOMER_SYNC_NAMESPACE_INCLUDE")
+	bindEnv("namespace-exclude", "HOMER_SYNC_NAMESPACE_EXCLUDE")
+	bindEnv("filter-mode", "HOMER_SYNC_FILTER_MODE")
 	bindEnv("gateway-names", "HOMER_SYNC_GATEWAY_NAMES")
+	bindEnv("gateway-sections", "HOMER_SYNC_GATEWAY_SECTIONS")
 	bindEnv("domain-suffixes", "HOMER_SYNC_DOMAIN_SUFFIXES")
+	bindEnv("domain-group-map", "HOMER_SYNC_DOMAIN_GROUP_MAP")
+	bindEnv("exclude-hostnames", "HOMER_SYNC_EXCLUDE_HOSTNAMES")
+	bindEnv("prefer-hostname-suffix", "HOMER_SYNC_PREFER_HOSTNAME_SUFFIX")
+	bindEnv("prefer-shortest", "HOMER_SYNC_PREFER_SHORTEST")
+	bindEnv("read-backend-annotations", "HOMER_SYNC_READ_BACKEND_ANNOTATIONS")
+	bindEnv("subtitle-fallback", "HOMER_SYNC_SUBTITLE_FALLBACK")
+	bindEnv("require-accepted", "HOMER_SYNC_REQUIRE_ACCEPTED")
+	bindEnv("output-kind", "HOMER_SYNC_OUTPUT_KIND")
+	bindEnv("apply-mode", "HOMER_SYNC_APPLY_MODE")
 	bindEnv("configmap-name", "HOMER_SYNC_CONFIGMAP_NAME")
+	bindEnv("configmap-key", "HOMER_SYNC_CONFIGMAP_KEY")
+	bindEnv("auto-icon", "HOMER_SYNC_AUTO_ICON")
+	bindEnv("icon-base-url", "HOMER_SYNC_ICON_BASE_URL")
 	bindEnv("configmap-namespace", "HOMER_SYNC_CONFIGMAP_NAMESPACE")
+	bindEnv("configmap-labels", "HOMER_SYNC_CONFIGMAP_LABELS")
+	bindEnv("configmap-annotations", "HOMER_SYNC_CONFIGMAP_ANNOTATIONS")
+	bindEnv("compress", "HOMER_SYNC_COMPRESS")
+	bindEnv("compress-threshold", "HOMER_SYNC_COMPRESS_THRESHOLD")
+	bindEnv("auto-shard", "HOMER_SYNC_AUTO_SHARD")
+	bindEnv("output-file", "HOMER_SYNC_OUTPUT_FILE")
+	bindEnv("output-kubeconfig", "HOMER_SYNC_OUTPUT_KUBECONFIG")
+	bindEnv("output-context", "HOMER_SYNC_OUTPUT_CONTEXT")
+	bindEnv("set-owner-reference", "HOMER_SYNC_SET_OWNER_REFERENCE")
+	bindEnv("configmap-map", "HOMER_SYNC_CONFIGMAP_MAP")
 	bindEnv("daemon", "HOMER_SYNC_DAEMON_MODE")
+	bindEnv("fail-on-initial-sync", "HOMER_SYNC_FAIL_ON_INITIAL_SYNC")
+	bindEnv("sync-on-shutdown", "HOMER_SYNC_SYNC_ON_SHUTDOWN")
+	bindEnv("shutdown-timeout", "HOMER_SYNC_SHUTDOWN_TIMEOUT")
+	bindEnv("force-sync", "HOMER_SYNC_FORCE_SYNC")
+	bindEnv("dry-run", "HOMER_SYNC_DRY_RUN")
+	bindEnv("emit-events", "HOMER_SYNC_EMIT_EVENTS")
 	bindEnv("scan-interval", "HOMER_SYNC_SCAN_INTERVAL")
+	bindEnv("namespace-cache-ttl", "HOMER_SYNC_NAMESPACE_CACHE_TTL")
+	bindEnv("workers", "HOMER_SYNC_WORKERS")
+	bindEnv("api-timeout", "HOMER_SYNC_API_TIMEOUT")
+	bindEnv("api-retries", "HOMER_SYNC_API_RETRIES")
+	bindEnv("once-timeout", "HOMER_SYNC_ONCE_TIMEOUT")
+	bindEnv("once-retries", "HOMER_SYNC_ONCE_RETRIES")
+	bindEnv("fail-on", "HOMER_SYNC_FAIL_ON")
+	bindEnv("health-addr", "HOMER_SYNC_HEALTH_ADDR")
+	bindEnv("self-exclude", "HOMER_SYNC_SELF_EXCLUDE")
+	bindEnv("self-name", "HOMER_SYNC_SELF_NAME")
 	bindEnv("log-level", "HOMER_SYNC_LOG_LEVEL")
+	bindEnv("log-format", "HOMER_SYNC_LOG_FORMAT")
 	bindEnv("title", "HOMER_SYNC_TITLE")
 	bindEnv("subtitle", "HOMER_SYNC_SUBTITLE")
 	bindEnv("columns", "HOMER_SYNC_COLUMNS")
+	bindEnv("theme", "HOMER_SYNC_THEME")
+	bindEnv("colors-file", "HOMER_SYNC_COLORS_FILE")
+	bindEnv("default-group-icon", "HOMER_SYNC_DEFAULT_GROUP_ICON")
 	bindEnv("template-path", "HOMER_SYNC_TEMPLATE_PATH")
+	bindEnv("template-dir", "HOMER_SYNC_TEMPLATE_DIR")
+	bindEnv("template-entry", "HOMER_SYNC_TEMPLATE_ENTRY")
+	bindEnv("homer-schema", "HOMER_SYNC_HOMER_SCHEMA")
+	bindEnv("no-header", "HOMER_SYNC_NO_HEADER")
+	bindEnv("canonicalize-yaml", "HOMER_SYNC_CANONICALIZE_YAML")
+	bindEnv("status-configmap", "HOMER_SYNC_STATUS_CONFIGMAP")
+	bindEnv("template-configmap", "HOMER_SYNC_TEMPLATE_CONFIGMAP")
+	bindEnv("template-configmap-key", "HOMER_SYNC_TEMPLATE_CONFIGMAP_KEY")
+	bindEnv("url-base", "HOMER_SYNC_URL_BASE")
+	bindEnv("url-rewrite", "HOMER_SYNC_URL_REWRITE")
+	bindEnv("url-rewrite-mode", "HOMER_SYNC_URL_REWRITE_MODE")
+	bindEnv("default-scheme", "HOMER_SYNC_DEFAULT_SCHEME")
+	bindEnv("default-target", "HOMER_SYNC_DEFAULT_TARGET")
+	bindEnv("no-url-normalize", "HOMER_SYNC_NO_URL_NORMALIZE")
+	bindEnv("label-to-tag", "HOMER_SYNC_LABEL_TO_TAG")
+	bindEnv("resolve-secrets", "HOMER_SYNC_RESOLVE_SECRETS")
+	bindEnv("group-order", "HOMER_SYNC_GROUP_ORDER")
+	bindEnv("group-order-by", "HOMER_SYNC_GROUP_ORDER_BY")
+	bindEnv("item-order-by", "HOMER_SYNC_ITEM_ORDER_BY")
+	bindEnv("sort-by", "HOMER_SYNC_SORT_BY")
+	bindEnv("on-duplicate", "HOMER_SYNC_ON_DUPLICATE")
+	bindEnv("dedupe", "HOMER_SYNC_DEDUPE")
+	bindEnv("group-by", "HOMER_SYNC_GROUP_BY")
+	bindEnv("disable-groups", "HOMER_SYNC_DISABLE_GROUPS")
+	bindEnv("strip-namespace-prefix", "HOMER_SYNC_STRIP_NAMESPACE_PREFIX")
+	bindEnv("strip-namespace-suffix", "HOMER_SYNC_STRIP_NAMESPACE_SUFFIX")
+	bindEnv("summary-group", "HOMER_SYNC_SUMMARY_GROUP")
+	bindEnv("summary-group-name", "HOMER_SYNC_SUMMARY_GROUP_NAME")
+	bindEnv("summary-group-icon", "HOMER_SYNC_SUMMARY_GROUP_ICON")
+	bindEnv("api-proxy-url", "HOMER_SYNC_API_PROXY_URL")
+	bindEnv("message-content", "HOMER_SYNC_MESSAGE_CONTENT")
+	bindEnv("message-style", "HOMER_SYNC_MESSAGE_STYLE")
+	bindEnv("message-title", "HOMER_SYNC_MESSAGE_TITLE")
+	bindEnv("message-icon", "HOMER_SYNC_MESSAGE_ICON")
+	bindEnv("maintenance-configmap", "HOMER_SYNC_MAINTENANCE_CONFIGMAP")
+	bindEnv("maintenance-key", "HOMER_SYNC_MAINTENANCE_KEY")
+	bindEnv("maintenance-style", "HOMER_SYNC_MAINTENANCE_STYLE")
+	bindEnv("maintenance-title", "HOMER_SYNC_MAINTENANCE_TITLE")
+	bindEnv("maintenance-icon", "HOMER_SYNC_MAINTENANCE_ICON")
 
 	return cmd
 }
@@ -87,16 +369,20 @@ func runE(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	setupLogging(cfg.LogLevel)
+	setupLogging(cfg.LogLevel, cfg.LogFormat)
 
 	slog.Info("homer-sync starting",
+		"component", "main",
 		"daemon", cfg.Daemon,
+		"dry_run", cfg.DryRun,
 		"interval", cfg.ScanInterval,
+		"sources", cfg.Sources,
+		"filter_mode", cfg.FilterMode,
 		"gateways", cfg.GatewayNames,
 		"domain_suffixes", cfg.DomainSuffixes,
 	)
 
-	clients, err := k8s.NewClients()
+	clients, err := k8s.NewClients(clientOptions(cfg))
 	if err != nil {
 		return fmt.Errorf("initialise kubernetes clients: %w", err)
 	}
@@ -105,20 +391,176 @@ func runE(cmd *cobra.Command, _ []string) error {
 	defer cancel()
 
 	ctrl := controller.New(clients, cfg)
-	return ctrl.Run(ctx)
+
+	if cfg.HealthAddr != "" {
+		go func() {
+			if err := health.Serve(ctx, cfg.HealthAddr, ctrl.Ready); err != nil {
+				slog.Error("health server stopped", "component", "health", "error", err)
+			}
+		}()
+	}
+
+	err = ctrl.Run(ctx)
+	if cfg.Daemon {
+		return err
+	}
+	return oneShotResult(cfg.FailOn, ctrl.LastSummary(), err)
+}
+
+// oneShotResult applies the --fail-on policy to the outcome of a one-shot run
+// and returns the error, if any, the process should exit with.
+func oneShotResult(policy string, sum controller.ScanSummary, err error) error {
+	switch {
+	case policy == "never":
+		if err != nil {
+			slog.Error("scan failed; exiting successfully per fail-on=never", "component", "main", "error", err)
+		}
+		return nil
+	case err != nil:
+		return err
+	case policy == "any-skip" && sum.Skipped > 0:
+		return fmt.Errorf("%d route(s) skipped (fail-on=any-skip)", sum.Skipped)
+	}
+	return nil
 }
 
 // buildConfig assembles Config from viper (flags + env vars).
 func buildConfig() (*config.Config, error) {
-	// viper returns comma-separated env vars as a single string; split manually.
-	gatewayNames := splitList(viper.GetString("gateway-names"))
-	if sl := viper.GetStringSlice("gateway-names"); len(sl) > 1 || (len(sl) == 1 && !strings.Contains(sl[0], ",")) {
-		gatewayNames = filterEmpty(sl)
+	// Viper already resolves flag > env > config file > default, so the file
+	// only has to be read before any value is looked up.
+	if path := viper.GetString("config"); path != "" {
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read config file %q: %w", path, err)
+		}
+	}
+	sources := getList("sources")
+	if err := config.ValidateSources(sources); err != nil {
+		return nil, err
+	}
+	routeKinds := getList("route-kinds")
+	if err := config.ValidateRouteKinds(routeKinds); err != nil {
+		return nil, err
+	}
+
+	selector := viper.GetString("route-label-selector")
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("invalid route-label-selector %q: %w", selector, err)
+	}
+
+	logFormat := strings.ToLower(viper.GetString("log-format"))
+	if logFormat != "text" && logFormat != "json" {
+		return nil, fmt.Errorf("invalid log-format %q: expected text or json", logFormat)
 	}
 
-	domainSuffixes := splitList(viper.GetString("domain-suffixes"))
-	if sl := viper.GetStringSlice("domain-suffixes"); len(sl) > 1 || (len(sl) == 1 && !strings.Contains(sl[0], ",")) {
-		domainSuffixes = filterEmpty(sl)
+	outputKind := strings.ToLower(viper.GetString("output-kind"))
+	if outputKind != "configmap" && outputKind != "secret" {
+		return nil, fmt.Errorf("invalid output-kind %q: expected configmap or secret", outputKind)
+	}
+
+	applyMode := strings.ToLower(viper.GetString("apply-mode"))
+	if applyMode != "update" && applyMode != "ssa" {
+		return nil, fmt.Errorf("invalid apply-mode %q: expected update or ssa", applyMode)
+	}
+
+	homerSchema := strings.ToLower(viper.GetString("homer-schema"))
+	if homerSchema != "v1" && homerSchema != "v2" {
+		return nil, fmt.Errorf("invalid homer-schema %q: expected v1 or v2", homerSchema)
+	}
+
+	failOn := strings.ToLower(viper.GetString("fail-on"))
+	if failOn != "render-error" && failOn != "any-skip" && failOn != "never" {
+		return nil, fmt.Errorf("invalid fail-on %q: expected render-error, any-skip or never", failOn)
+	}
+
+	defaultScheme := strings.ToLower(viper.GetString("default-scheme"))
+	if defaultScheme != "auto" && defaultScheme != "https" && defaultScheme != "http" {
+		return nil, fmt.Errorf("invalid default-scheme %q: expected auto, https or http", defaultScheme)
+	}
+
+	defaultTarget := strings.TrimSpace(viper.GetString("default-target"))
+	if defaultTarget != "" && !slices.Contains(config.LinkTargets, defaultTarget) {
+		return nil, fmt.Errorf("invalid default-target %q: expected one of %s, or empty", defaultTarget, strings.Join(config.LinkTargets, ", "))
+	}
+
+	rewrites, err := config.ParseURLRewrites(viper.GetString("url-rewrite"))
+	if err != nil {
+		return nil, err
+	}
+	rewriteMode := strings.ToLower(viper.GetString("url-rewrite-mode"))
+	if rewriteMode != "first" && rewriteMode != "all" {
+		return nil, fmt.Errorf("invalid url-rewrite-mode %q: expected first or all", rewriteMode)
+	}
+
+	urlBase := viper.GetString("url-base")
+	if _, err := url.Parse(urlBase); err != nil {
+		return nil, fmt.Errorf("invalid url-base %q: %w", urlBase, err)
+	}
+
+	colors, err := config.LoadColors(viper.GetString("colors-file"))
+	if err != nil {
+		return nil, err
+	}
+
+	labelTags, err := config.ParseLabelTags(getList("label-to-tag"))
+	if err != nil {
+		return nil, err
+	}
+
+	domainGroups, err := config.ParseDomainGroups(getList("domain-group-map"))
+	if err != nil {
+		return nil, err
+	}
+
+	outputLabels, err := config.ParseKeyValues("configmap-labels", getList("configmap-labels"))
+	if err != nil {
+		return nil, err
+	}
+	outputAnnotations, err := config.ParseKeyValues("configmap-annotations", getList("configmap-annotations"))
+	if err != nil {
+		return nil, err
+	}
+
+	order := config.OrderPolicy{
+		GroupOrder: getList("group-order"),
+		GroupKeys:  getList("group-order-by"),
+		ItemKeys:   getList("item-order-by"),
+	}
+	switch sortBy := strings.ToLower(viper.GetString("sort-by")); sortBy {
+	case "manual":
+	case "created":
+		// Newest first, with the configured keys breaking ties.
+		rest := slices.DeleteFunc(slices.Clone(order.ItemKeys), func(k string) bool { return k == "created" })
+		order.ItemKeys = append([]string{"created"}, rest...)
+	default:
+		return nil, fmt.Errorf("invalid sort-by %q: expected manual or created", sortBy)
+	}
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	onDuplicate := strings.ToLower(viper.GetString("on-duplicate"))
+	if !slices.Contains([]string{"warn", "suffix", "skip"}, onDuplicate) {
+		return nil, fmt.Errorf("invalid on-duplicate %q: expected warn, suffix or skip", onDuplicate)
+	}
+
+	outputMap, err := config.ParseOutputMap(viper.GetString("configmap-map"))
+	if err != nil {
+		return nil, err
+	}
+
+	groupBy, err := config.ParseGroupBy(viper.GetString("group-by"))
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := config.ParseSummaryGroup(
+		viper.GetString("summary-group"),
+		viper.GetString("summary-group-name"),
+		viper.GetString("summary-group-icon"),
+	)
+	if err != nil {
+		return nil, err
 	}
 
 	ns := viper.GetString("configmap-namespace")
@@ -126,23 +568,177 @@ func buildConfig() (*config.Config, error) {
 		ns = config.DetectNamespace()
 	}
 
-	return &config.Config{
-		GatewayNames:       gatewayNames,
-		DomainSuffixes:     domainSuffixes,
+	selfNS, selfName := config.DetectSelf()
+	if n := viper.GetString("self-name"); n != "" {
+		selfName = n
+	}
+
+	var tmplSource config.TemplateSource
+	if ref := viper.GetString("template-configmap"); ref != "" {
+		tNS, tName := config.ParseObjectRef(ref, ns)
+		tmplSource = config.TemplateSource{
+			Namespace: tNS,
+			Name:      tName,
+			Key:       viper.GetString("template-configmap-key"),
+		}
+	}
+
+	var status config.ObjectRef
+	if ref := viper.GetString("status-configmap"); ref != "" {
+		status.Namespace, status.Name = config.ParseObjectRef(ref, ns)
+	}
+
+	var maintenance config.MaintenanceSource
+	if ref := viper.GetString("maintenance-configmap"); ref != "" {
+		mNS, mName := config.ParseObjectRef(ref, ns)
+		maintenance = config.MaintenanceSource{
+			Namespace: mNS,
+			Name:      mName,
+			Key:       viper.GetString("maintenance-key"),
+			Style:     viper.GetString("maintenance-style"),
+			Title:     viper.GetString("maintenance-title"),
+			Icon:      viper.GetString("maintenance-icon"),
+		}
+	}
+
+	cfg := &config.Config{
+		Sources:            sources,
+		RouteKinds:         routeKinds,
+		RouteLabelSelector: selector,
+		NamespaceInclude:   getList("namespace-include"),
+		NamespaceExclude:   getList("namespace-exclude"),
+		GatewayNames:       getList("gateway-names"),
+		GatewaySections:    getList("gateway-sections"),
+		DomainSuffixes:     getList("domain-suffixes"),
+		DomainGroups:       domainGroups,
+		ExcludeHostnames:   getList("exclude-hostnames"),
+		PreferSuffixes:     getList("prefer-hostname-suffix"),
+		PreferShortest:     viper.GetBool("prefer-shortest"),
+		DisableGroups:      getList("disable-groups"),
+		StripNSPrefixes:    getList("strip-namespace-prefix"),
+		StripNSSuffixes:    getList("strip-namespace-suffix"),
+		BackendAnnotations: viper.GetBool("read-backend-annotations"),
+		SubtitleFallback:   getList("subtitle-fallback"),
+		RequireAccepted:    viper.GetBool("require-accepted"),
+		OutputKind:         outputKind,
+		ApplyMode:          applyMode,
 		ConfigMapName:      viper.GetString("configmap-name"),
+		ConfigMapKey:       viper.GetString("configmap-key"),
+		AutoIcon:           viper.GetBool("auto-icon"),
+		IconBaseURL:        viper.GetString("icon-base-url"),
 		ConfigMapNamespace: ns,
+		OutputLabels:       outputLabels,
+		OutputAnnotations:  outputAnnotations,
+		Compress:           viper.GetBool("compress"),
+		CompressThreshold:  viper.GetInt("compress-threshold"),
+		AutoShard:          viper.GetBool("auto-shard"),
+		OutputMap:          outputMap,
+		OutputFile:         viper.GetString("output-file"),
+		Kubeconfig:         viper.GetString("kubeconfig"),
+		KubeContext:        viper.GetString("context"),
+		OutputKubeconfig:   viper.GetString("output-kubeconfig"),
+		OutputContext:      viper.GetString("output-context"),
 		Daemon:             viper.GetBool("daemon"),
+		FailOnInitialSync:  viper.GetBool("fail-on-initial-sync"),
+		SyncOnShutdown:     viper.GetBool("sync-on-shutdown"),
+		ShutdownTimeout:    viper.GetInt("shutdown-timeout"),
+		ForceSync:          viper.GetBool("force-sync"),
+		DryRun:             viper.GetBool("dry-run"),
+		EmitEvents:         viper.GetBool("emit-events"),
 		ScanInterval:       viper.GetInt("scan-interval"),
+		NamespaceCacheTTL:  viper.GetInt("namespace-cache-ttl"),
+		OnceTimeout:        viper.GetInt("once-timeout"),
+		APITimeout:         viper.GetInt("api-timeout"),
+		Workers:            viper.GetInt("workers"),
+		APIRetries:         viper.GetInt("api-retries"),
+		OnceRetries:        viper.GetInt("once-retries"),
+		FailOn:             failOn,
+		HealthAddr:         viper.GetString("health-addr"),
 		LogLevel:           config.ParseLogLevel(viper.GetString("log-level")),
+		LogFormat:          logFormat,
 		Title:              viper.GetString("title"),
 		Subtitle:           viper.GetString("subtitle"),
 		Columns:            viper.GetInt("columns"),
+		Theme:              viper.GetString("theme"),
+		Colors:             colors,
+		DefaultGroupIcon:   viper.GetString("default-group-icon"),
 		TemplatePath:       viper.GetString("template-path"),
-	}, nil
+		TemplateDir:        viper.GetString("template-dir"),
+		TemplateEntry:      viper.GetString("template-entry"),
+		HomerSchema:        homerSchema,
+		TemplateConfigMap:  tmplSource,
+		NoHeader:           viper.GetBool("no-header"),
+		CanonicalYAML:      viper.GetBool("canonicalize-yaml"),
+		URLBase:            urlBase,
+		URLRewrites:        rewrites,
+		URLRewriteAll:      rewriteMode == "all",
+		DefaultScheme:      defaultScheme,
+		DefaultTarget:      defaultTarget,
+		NoURLNormalize:     viper.GetBool("no-url-normalize"),
+		LabelTags:          labelTags,
+		ResolveSecrets:     viper.GetBool("resolve-secrets"),
+		SummaryGroup:       summary,
+		GroupBy:            groupBy,
+		Order:              order,
+		OnDuplicate:        onDuplicate,
+		Dedupe:             viper.GetBool("dedupe"),
+		APIProxyURL:        viper.GetString("api-proxy-url"),
+		Maintenance:        maintenance,
+		StatusConfigMap:    status,
+		Message: config.StaticMessage{
+			Style:   viper.GetString("message-style"),
+			Title:   viper.GetString("message-title"),
+			Icon:    viper.GetString("message-icon"),
+			Content: viper.GetString("message-content"),
+		},
+		SelfExclude:       viper.GetBool("self-exclude"),
+		SelfNamespace:     selfNS,
+		SelfName:          selfName,
+		PodName:           os.Getenv("POD_NAME"),
+		SetOwnerReference: viper.GetBool("set-owner-reference"),
+	}
+	if cfg.NamespaceCacheTTL < 0 {
+		cfg.NamespaceCacheTTL = cfg.ScanInterval
+	}
+	if err := cfg.ResolveFilterMode(strings.ToLower(viper.GetString("filter-mode"))); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateResolveSecrets(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// getList reads a list-valued key from viper. Flags and config-file lists
+// arrive as a proper slice, but comma-separated env vars (and plain strings in
+// a config file) come back as a single string; split manually.
+func getList(key string) []string {
+	if v, ok := viper.Get(key).([]interface{}); ok {
+		return filterEmpty(cast.ToStringSlice(v))
+	}
+	if sl := viper.GetStringSlice(key); len(sl) > 1 || (len(sl) == 1 && !strings.Contains(sl[0], ",")) {
+		return filterEmpty(sl)
+	}
+	return splitList(viper.GetString(key))
 }
 
-func setupLogging(level slog.Level) {
-	h := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+// clientOptions returns the Kubernetes client options for cfg.
+func clientOptions(cfg *config.Config) k8s.Options {
+	return k8s.Options{
+		Kubeconfig:       cfg.Kubeconfig,
+		Context:          cfg.KubeContext,
+		ProxyURL:         cfg.APIProxyURL,
+		OutputKubeconfig: cfg.OutputKubeconfig,
+		OutputContext:    cfg.OutputContext,
+	}
+}
+
+func setupLogging(level slog.Level, format string) {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if format == "json" {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
 	slog.SetDefault(slog.New(h))
 }
 
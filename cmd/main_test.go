@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// TestBuildConfigDefaults checks that the flags add nothing on top of
+// config.Default, so embedders get the same configuration as the CLI.
+func TestBuildConfigDefaults(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	newRootCmd()
+
+	got, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig: %v", err)
+	}
+	want := config.Default()
+	// Detected from the pod, not defaulted.
+	want.ConfigMapNamespace, want.SelfNamespace, want.SelfName, want.PodName = got.ConfigMapNamespace, got.SelfNamespace, got.SelfName, got.PodName
+
+	if len(got.Order.GroupOrder) == 0 {
+		got.Order.GroupOrder = nil
+	}
+
+	gv, wv := reflect.ValueOf(got).Elem(), reflect.ValueOf(want).Elem()
+	for i := 0; i < gv.NumField(); i++ {
+		g, w := gv.Field(i), wv.Field(i)
+		// An empty list or map from the flags equals an unset one.
+		if k := g.Kind(); (k == reflect.Slice || k == reflect.Map) && g.Len() == 0 && w.Len() == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(g.Interface(), w.Interface()) {
+			t.Errorf("%s = %#v by default, config.Default has %#v", gv.Type().Field(i).Name, g.Interface(), w.Interface())
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mirceanton/homer-sync/internal/controller"
+	"github.com/mirceanton/homer-sync/internal/k8s"
+)
+
+// newPreflightCmd returns the preflight subcommand, which checks the RBAC
+// permissions and APIs the current configuration needs without syncing.
+func newPreflightCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "preflight",
+		Short: "Check RBAC permissions and served APIs for the current configuration",
+		Args:  cobra.NoArgs,
+		// A failed check is reported in the table, not as a usage problem.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := buildConfig()
+			if err != nil {
+				return err
+			}
+			setupLogging(cfg.LogLevel, cfg.LogFormat)
+
+			clients, err := k8s.NewClients(clientOptions(cfg))
+			if err != nil {
+				return fmt.Errorf("initialise kubernetes clients: %w", err)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+			return controller.New(clients, cfg).Preflight(ctx, cmd.OutOrStdout())
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mirceanton/homer-sync/internal/controller"
+)
+
+// newValidateTemplateCmd returns the validate-template subcommand, which
+// renders a custom template against sample data without touching a cluster.
+func newValidateTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate-template",
+		Short: "Render a custom template against sample data and report any errors",
+		Args:  cobra.NoArgs,
+		// Errors here are about the template, not the invocation.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, _ := cmd.Flags().GetString("template-path")
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read template %q: %w", path, err)
+			}
+			out, err := controller.RenderSample(string(raw))
+			if err != nil {
+				return fmt.Errorf("template %q: %w", path, err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+	cmd.Flags().String("template-path", "", "Path to the Go template file to validate")
+	_ = cmd.MarkFlagRequired("template-path")
+	return cmd
+}
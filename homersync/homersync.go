@@ -0,0 +1,87 @@
+// Package homersync embeds the homer-sync scan and render logic in other
+// programs: it builds the Homer config from the cluster's routes without
+// writing any ConfigMap or Secret.
+package homersync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+	"github.com/mirceanton/homer-sync/internal/controller"
+	"github.com/mirceanton/homer-sync/internal/k8s"
+)
+
+// Config holds the homer-sync configuration. Its fields mirror the CLI flags,
+// but a zero value is not the CLI default: start from DefaultConfig. Options
+// that only affect writing the output, and EmitEvents, are ignored by Sync
+// and SyncAll.
+type Config = config.Config
+
+// Types used by Config fields.
+type (
+	MaintenanceSource = config.MaintenanceSource
+	TemplateSource    = config.TemplateSource
+	ObjectRef         = config.ObjectRef
+	StaticMessage     = config.StaticMessage
+	OutputMapping     = config.OutputMapping
+	OrderPolicy       = config.OrderPolicy
+	SummaryGroup      = config.SummaryGroup
+	GroupBy           = config.GroupBy
+	URLRewrite        = config.URLRewrite
+	LabelTag          = config.LabelTag
+	DomainGroup       = config.DomainGroup
+)
+
+// Clients are the Kubernetes API clients a scan reads through.
+type Clients = k8s.Clients
+
+// ClientOptions tweaks how NewClients connects to the cluster.
+type ClientOptions = k8s.Options
+
+// NewClients builds Kubernetes API clients, preferring in-cluster config and
+// falling back to the local kubeconfig, unless a kubeconfig is given.
+func NewClients(opts ClientOptions) (*Clients, error) {
+	return k8s.NewClients(opts)
+}
+
+// DefaultConfig returns the configuration the homer-sync CLI runs with when no
+// flag or environment variable is set. ConfigMapNamespace, which the CLI
+// detects from its pod, is left empty: set it when cfg reads a template or
+// maintenance ConfigMap.
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// DefaultOrderPolicy returns the group and item order the CLI uses by default.
+func DefaultOrderPolicy() OrderPolicy {
+	return config.DefaultOrderPolicy()
+}
+
+// Sync runs a single scan and returns the config rendered for the default
+// output, cfg.ConfigMapName. Nothing is written to the cluster. Use SyncAll
+// when cfg splits the dashboard across several outputs or shards.
+func Sync(ctx context.Context, clients *Clients, cfg *Config) (string, error) {
+	configs, err := SyncAll(ctx, clients, cfg)
+	if err != nil {
+		return "", err
+	}
+	// A sharded config keeps its first shard under the unsuffixed name;
+	// the rest would be lost.
+	if _, sharded := configs[cfg.ConfigMapName+"-1"]; sharded {
+		return "", fmt.Errorf("config for %s was sharded; use SyncAll", cfg.ConfigMapName)
+	}
+	return configs[cfg.ConfigMapName], nil
+}
+
+// SyncAll runs a single scan and returns the rendered config of every output
+// object keyed by name, with --auto-shard shards after the first under their
+// numbered names.
+// It only reads from the cluster (routes, namespaces and any template or
+// maintenance ConfigMap); nothing is written, not even Events.
+// Start cfg from DefaultConfig to get the same output as the CLI.
+func SyncAll(ctx context.Context, clients *Clients, cfg *Config) (map[string]string, error) {
+	readOnly := *cfg
+	readOnly.EmitEvents = false
+	return controller.New(clients, &readOnly).Render(ctx)
+}
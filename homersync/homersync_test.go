@@ -0,0 +1,91 @@
+package homersync_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwfake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/mirceanton/homer-sync/homersync"
+)
+
+func testClients() (*homersync.Clients, *fake.Clientset) {
+	core := fake.NewClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "media"}})
+	route := &gwv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "media",
+		Name:        "jellyfin",
+		Annotations: map[string]string{"home.mirceanton.com/enabled": "true"},
+	}}
+	route.Spec.Hostnames = []gwv1.Hostname{"jellyfin.example.com"}
+	return &homersync.Clients{Core: core, Gateway: gwfake.NewSimpleClientset(route)}, core
+}
+
+func testConfig() *homersync.Config {
+	return &homersync.Config{
+		Sources:            []string{"httproute"},
+		ConfigMapName:      "homer-config",
+		ConfigMapNamespace: "default",
+		Order:              homersync.DefaultOrderPolicy(),
+		EmitEvents:         true,
+	}
+}
+
+func TestSync(t *testing.T) {
+	clients, core := testClients()
+	rendered, err := homersync.Sync(context.Background(), clients, testConfig())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !strings.Contains(rendered, "https://jellyfin.example.com") {
+		t.Errorf("rendered config lacks the route:\n%s", rendered)
+	}
+	cms, _ := core.CoreV1().ConfigMaps("").List(context.Background(), metav1.ListOptions{})
+	if len(cms.Items) != 0 {
+		t.Errorf("Sync wrote %d ConfigMaps", len(cms.Items))
+	}
+}
+
+func TestSyncDefaultConfig(t *testing.T) {
+	clients, _ := testClients()
+	all, err := homersync.SyncAll(context.Background(), clients, homersync.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SyncAll: %v", err)
+	}
+	rendered, ok := all["homer-config"]
+	if !ok || len(all) != 1 {
+		t.Fatalf("SyncAll returned %d outputs, want only homer-config", len(all))
+	}
+	for _, want := range []string{"title: \"Home Dashboard\"", "https://jellyfin.example.com"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered config lacks %s:\n%s", want, rendered)
+		}
+	}
+}
+
+func TestSyncAllWritesNoEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.tmpl")
+	if err := os.WriteFile(path, []byte("{{ .NoSuchField }}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.TemplatePath = path
+
+	clients, core := testClients()
+	if _, err := homersync.SyncAll(context.Background(), clients, cfg); err == nil {
+		t.Fatal("SyncAll succeeded with a broken template")
+	}
+	events, _ := core.CoreV1().Events("").List(context.Background(), metav1.ListOptions{})
+	if len(events.Items) != 0 {
+		t.Errorf("SyncAll recorded %d events", len(events.Items))
+	}
+	if !cfg.EmitEvents {
+		t.Error("SyncAll modified the caller's config")
+	}
+}
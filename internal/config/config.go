@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -11,24 +16,371 @@ const (
 	saNamespaceFile  = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 )
 
+// LinkTargets are the link target values Homer documents for items.
+var LinkTargets = []string{"_blank", "_self", "_parent", "_top"}
+
 // Config holds all runtime configuration for homer-sync.
 type Config struct {
+	Sources            []string
+	RouteKinds         []string
+	RouteLabelSelector string
+	RequireAccepted    bool
+	BackendAnnotations bool
+	SubtitleFallback   []string
+	NamespaceInclude   []string
+	NamespaceExclude   []string
 	GatewayNames       []string
+	GatewaySections    []string
 	DomainSuffixes     []string
+	DomainGroups       []DomainGroup
+	DisableGroups      []string
+	ExcludeHostnames   []string
+	PreferSuffixes     []string
+	PreferShortest     bool
+	StripNSPrefixes    []string
+	StripNSSuffixes    []string
+	FilterMode         string
+	OutputKind         string
+	ApplyMode          string
 	ConfigMapName      string
+	ConfigMapKey       string
 	ConfigMapNamespace string
+	OutputLabels       map[string]string
+	OutputAnnotations  map[string]string
+	Compress           bool
+	CompressThreshold  int
+	AutoShard          bool
+	OutputMap          []OutputMapping
+	OutputFile         string
+	Kubeconfig         string
+	KubeContext        string
+	OutputKubeconfig   string
+	OutputContext      string
 	Daemon             bool
+	FailOnInitialSync  bool
+	SyncOnShutdown     bool
+	ForceSync          bool
+	ShutdownTimeout    int
+	DryRun             bool
+	EmitEvents         bool
 	ScanInterval       int
+	NamespaceCacheTTL  int
+	Workers            int
+	OnceTimeout        int
+	APITimeout         int
+	APIRetries         int
+	OnceRetries        int
+	FailOn             string
+	HealthAddr         string
 	LogLevel           slog.Level
+	LogFormat          string
 	Title              string
 	Subtitle           string
+	Theme              string
+	Colors             map[string]interface{}
 	Columns            int
+	DefaultGroupIcon   string
 	TemplatePath       string
+	TemplateDir        string
+	TemplateEntry      string
+	HomerSchema        string
+	TemplateConfigMap  TemplateSource
+	NoHeader           bool
+	CanonicalYAML      bool
+	URLBase            string
+	URLRewrites        []URLRewrite
+	URLRewriteAll      bool
+	DefaultScheme      string
+	DefaultTarget      string
+	NoURLNormalize     bool
+	AutoIcon           bool
+	IconBaseURL        string
+	LabelTags          []LabelTag
+	ResolveSecrets     bool
+	SummaryGroup       SummaryGroup
+	GroupBy            GroupBy
+	Order              OrderPolicy
+	OnDuplicate        string
+	Dedupe             bool
+	APIProxyURL        string
+	Maintenance        MaintenanceSource
+	StatusConfigMap    ObjectRef
+	Message            StaticMessage
+	SelfExclude        bool
+	SelfNamespace      string
+	SelfName           string
+	PodName            string
+	SetOwnerReference  bool
+}
+
+// Default returns the configuration the CLI runs with when no flag, env var
+// or config file sets anything, and is where the flag defaults come from.
+// ConfigMapNamespace, SelfNamespace, SelfName and PodName are left empty:
+// the CLI detects them from the pod it runs in.
+func Default() *Config {
+	return &Config{
+		Sources:           []string{"httproute"},
+		FilterMode:        FilterModeOptIn,
+		OutputKind:        "configmap",
+		ApplyMode:         "update",
+		ConfigMapName:     "homer-config",
+		ConfigMapKey:      "config.yml",
+		CompressThreshold: 768 * 1024,
+		Daemon:            true,
+		ShutdownTimeout:   10,
+		ScanInterval:      300,
+		NamespaceCacheTTL: 300,
+		APITimeout:        30,
+		APIRetries:        3,
+		FailOn:            "render-error",
+		LogLevel:          slog.LevelInfo,
+		LogFormat:         "text",
+		Title:             "Home Dashboard",
+		Columns:           5,
+		DefaultGroupIcon:  "fas fa-globe",
+		TemplateEntry:     "homer",
+		HomerSchema:       "v1",
+		DefaultScheme:     "auto",
+		DefaultTarget:     "_blank",
+		IconBaseURL:       "https://cdn.jsdelivr.net/gh/homarr-labs/dashboard-icons/png",
+		GroupBy:           GroupBy{Source: "namespace"},
+		Order:             DefaultOrderPolicy(),
+		OnDuplicate:       "warn",
+		Message:           StaticMessage{Style: "is-info", Icon: "fas fa-info-circle"},
+		SelfExclude:       true,
+	}
+}
+
+// MaintenanceSource points at a ConfigMap key whose content, when non-empty,
+// is rendered as a maintenance banner. An empty Name disables it.
+type MaintenanceSource struct {
+	Namespace string
+	Name      string
+	Key       string
+	Style     string
+	Title     string
+	Icon      string
+}
+
+// TemplateSource points at a ConfigMap key holding a custom template. An empty
+// Name disables it.
+type TemplateSource struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// ObjectRef names a namespaced object, such as the --status-configmap.
+type ObjectRef struct {
+	Namespace string
+	Name      string
+}
+
+// StaticMessage is a fixed Homer message set from flags. An empty Content
+// disables it.
+type StaticMessage struct {
+	Style   string
+	Title   string
+	Icon    string
+	Content string
+}
+
+// OutputMapping routes the listed groups to an extra ConfigMap named Name.
+type OutputMapping struct {
+	Name   string
+	Groups []string
+}
+
+// LoadColors reads a YAML file holding Homer's colors block, e.g. top-level
+// light and dark palettes. An empty path yields nil.
+func LoadColors(path string) (map[string]interface{}, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read colors file %q: %w", path, err)
+	}
+	var colors map[string]interface{}
+	if err := yaml.Unmarshal(raw, &colors); err != nil {
+		return nil, fmt.Errorf("parse colors file %q: %w", path, err)
+	}
+	return colors, nil
+}
+
+// ParseOutputMap parses "name=GroupA,GroupB;other=GroupC" into mappings.
+func ParseOutputMap(spec string) ([]OutputMapping, error) {
+	var out []OutputMapping
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, groups, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid configmap-map entry %q: expected name=Group1,Group2", entry)
+		}
+		m := OutputMapping{Name: name}
+		for _, g := range strings.Split(groups, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				m.Groups = append(m.Groups, g)
+			}
+		}
+		if len(m.Groups) == 0 {
+			return nil, fmt.Errorf("invalid configmap-map entry %q: no groups listed", entry)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// SupportedSources lists the resource kinds homer-sync can scan.
+var SupportedSources = []string{"httproute", "ingress", "openshift-route"}
+
+// SupportedRouteKinds lists the additional Gateway API route kinds that can be
+// scanned on top of the configured sources.
+var SupportedRouteKinds = []string{"grpcroute", "tcproute"}
+
+// ValidateSources rejects an empty or unknown source list.
+func ValidateSources(sources []string) error {
+	return validateKeys("sources", sources, SupportedSources)
+}
+
+// ValidateRouteKinds rejects unknown route kinds. An empty list is valid.
+func ValidateRouteKinds(kinds []string) error {
+	if len(kinds) == 0 {
+		return nil
+	}
+	return validateKeys("route-kinds", kinds, SupportedRouteKinds)
+}
+
+// OrderPolicy is the single ordering contract for the rendered config. Groups
+// and the items within each group are compared key by key; the first key that
+// differs decides. Items always fall back to their URL as the final tie-break so
+// output is deterministic even for identical names.
+type OrderPolicy struct {
+	// GroupOrder lists group names that come first, in this order. Groups not
+	// listed follow, ordered by GroupKeys.
+	GroupOrder []string
+	GroupKeys  []string
+	ItemKeys   []string
+}
+
+// Supported ordering keys.
+var (
+	GroupOrderKeys = []string{"sort", "name"}
+	ItemOrderKeys  = []string{"sort", "sort-key", "name", "url", "created"}
+)
+
+// DefaultOrderPolicy orders groups by group-sort value, then name, and items by
+// sort value, then sort key, then name.
+func DefaultOrderPolicy() OrderPolicy {
+	return OrderPolicy{GroupKeys: []string{"sort", "name"}, ItemKeys: []string{"sort", "sort-key", "name"}}
+}
+
+// Validate rejects unknown or empty key lists.
+func (p OrderPolicy) Validate() error {
+	if err := validateKeys("group", p.GroupKeys, GroupOrderKeys); err != nil {
+		return err
+	}
+	return validateKeys("item", p.ItemKeys, ItemOrderKeys)
+}
+
+func validateKeys(kind string, keys, allowed []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("%s order: at least one key is required", kind)
+	}
+	for _, k := range keys {
+		if !slices.Contains(allowed, k) {
+			return fmt.Errorf("%s order: unknown key %q (allowed: %s)", kind, k, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// SummaryGroup configures the optional synthetic group aggregating services
+// from every other group. A zero value (empty Mode) disables it.
+type SummaryGroup struct {
+	Mode  string // "all" or "recent"
+	Limit int    // number of services kept in "recent" mode
+	Name  string
+	Icon  string
+}
+
+// GroupBy selects where a namespace's default group name comes from. Source is
+// "namespace", "annotation" or "label"; Key names the annotation or label.
+type GroupBy struct {
+	Source string
+	Key    string
+}
+
+// URLRewrite rewrites service links matching Pattern to Replacement, which
+// may reference capture groups as $1 or ${name}.
+type URLRewrite struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// LabelTag maps a namespace label value to a Homer tag style.
+type LabelTag struct {
+	Label    string
+	Value    string
+	TagStyle string
+}
+
+// DomainGroup assigns routes whose linked hostname matches Suffix (a literal
+// suffix or glob) to Group.
+type DomainGroup struct {
+	Suffix string
+	Group  string
+}
+
+// RemoteOutput reports whether the rendered config is written to a different
+// cluster than the one routes are read from.
+func (c *Config) RemoteOutput() bool {
+	return c.OutputKubeconfig != "" || c.OutputContext != ""
 }
 
 // HasFilters returns true when at least one opt-out filter is active.
 func (c *Config) HasFilters() bool {
-	return len(c.GatewayNames) > 0 || len(c.DomainSuffixes) > 0
+	return len(c.GatewayNames) > 0 || len(c.GatewaySections) > 0 || len(c.DomainSuffixes) > 0
+}
+
+// Filter modes decide how the enabled annotation and the gateway/domain
+// filters combine.
+const (
+	FilterModeOptIn  = "opt-in"  // only routes annotated enabled=true
+	FilterModeOptOut = "opt-out" // routes matching the filters unless enabled=false
+	FilterModeStrict = "strict"  // routes annotated enabled=true that also match the filters
+)
+
+// ResolveFilterMode validates mode. An empty mode is inferred the historical
+// way: opt-out when any filter is set, opt-in otherwise.
+func (c *Config) ResolveFilterMode(mode string) error {
+	switch mode {
+	case "":
+		c.FilterMode = FilterModeOptIn
+		if c.HasFilters() {
+			c.FilterMode = FilterModeOptOut
+		}
+		return nil
+	case FilterModeOptIn, FilterModeOptOut, FilterModeStrict:
+		c.FilterMode = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid filter-mode %q: expected opt-in, opt-out or strict", mode)
+	}
+}
+
+// ValidateResolveSecrets rejects --resolve-secrets unless the output is a
+// Secret: resolved API keys are rendered in plain text, and a ConfigMap would
+// expose them to anyone who can read ConfigMaps.
+func (c *Config) ValidateResolveSecrets() error {
+	if c.ResolveSecrets && c.OutputKind != "secret" {
+		return fmt.Errorf("resolve-secrets requires output-kind secret, got %q: resolved API keys would be stored in plain text", c.OutputKind)
+	}
+	return nil
 }
 
 // ParseLogLevel converts a level string (debug/info/warn/error) to slog.Level.
@@ -55,3 +407,163 @@ func DetectNamespace() string {
 	}
 	return strings.TrimSpace(string(data))
 }
+
+// ParseLabelTags parses "label=value=>tagstyle" entries into LabelTag rules.
+func ParseLabelTags(entries []string) ([]LabelTag, error) {
+	rules := make([]LabelTag, 0, len(entries))
+	for _, e := range entries {
+		match, style, ok := strings.Cut(e, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid label-to-tag entry %q: expected label=value=>tagstyle", e)
+		}
+		label, value, ok := strings.Cut(match, "=")
+		label, value, style = strings.TrimSpace(label), strings.TrimSpace(value), strings.TrimSpace(style)
+		if !ok || label == "" || value == "" || style == "" {
+			return nil, fmt.Errorf("invalid label-to-tag entry %q: expected label=value=>tagstyle", e)
+		}
+		rules = append(rules, LabelTag{Label: label, Value: value, TagStyle: style})
+	}
+	return rules, nil
+}
+
+// ParseKeyValues parses "key=value" entries of the named option into a map.
+func ParseKeyValues(option string, entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	kv := make(map[string]string, len(entries))
+	for _, e := range entries {
+		k, v, ok := strings.Cut(e, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: expected key=value", option, e)
+		}
+		kv[k] = v
+	}
+	return kv, nil
+}
+
+// ParseDomainGroups parses "suffix=group" entries into DomainGroup rules,
+// keeping their order.
+func ParseDomainGroups(entries []string) ([]DomainGroup, error) {
+	rules := make([]DomainGroup, 0, len(entries))
+	for _, e := range entries {
+		suffix, group, ok := strings.Cut(e, "=")
+		suffix, group = strings.TrimSpace(suffix), strings.TrimSpace(group)
+		if !ok || suffix == "" || group == "" {
+			return nil, fmt.Errorf("invalid domain-group-map entry %q: expected suffix=group", e)
+		}
+		rules = append(rules, DomainGroup{Suffix: suffix, Group: group})
+	}
+	return rules, nil
+}
+
+// ParseSummaryGroup parses a "all" or "recent:N" summary group spec. An empty
+// spec disables the summary group. Name falls back to a mode-specific default.
+func ParseSummaryGroup(spec, name, icon string) (SummaryGroup, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return SummaryGroup{}, nil
+	case spec == "all":
+		return SummaryGroup{Mode: "all", Name: stringOr(name, "All Services"), Icon: icon}, nil
+	case strings.HasPrefix(spec, "recent:"):
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(spec, "recent:"), "%d", &n); err != nil || n <= 0 {
+			return SummaryGroup{}, fmt.Errorf("invalid summary-group %q: recent count must be a positive integer", spec)
+		}
+		return SummaryGroup{Mode: "recent", Limit: n, Name: stringOr(name, "Recently Added"), Icon: icon}, nil
+	default:
+		return SummaryGroup{}, fmt.Errorf("invalid summary-group %q: expected all or recent:N", spec)
+	}
+}
+
+// ParseURLRewrites parses a ";"-separated list of regex=>replacement rules.
+// Semicolons rather than commas separate rules so regexes may use {m,n}.
+func ParseURLRewrites(spec string) ([]URLRewrite, error) {
+	var rules []URLRewrite
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, repl, ok := strings.Cut(entry, "=>")
+		pattern, repl = strings.TrimSpace(pattern), strings.TrimSpace(repl)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid url-rewrite entry %q: expected regex=>replacement", entry)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url-rewrite entry %q: %w", entry, err)
+		}
+		rules = append(rules, URLRewrite{Pattern: re, Replacement: repl})
+	}
+	return rules, nil
+}
+
+// ParseGroupBy parses a "namespace", "annotation:<key>" or "label:<key>" group-by
+// spec. An empty spec means "namespace".
+func ParseGroupBy(spec string) (GroupBy, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "namespace" {
+		return GroupBy{Source: "namespace"}, nil
+	}
+	source, key, ok := strings.Cut(spec, ":")
+	if !ok || (source != "annotation" && source != "label") || strings.TrimSpace(key) == "" {
+		return GroupBy{}, fmt.Errorf("invalid group-by %q: expected namespace, annotation:<key> or label:<key>", spec)
+	}
+	return GroupBy{Source: source, Key: strings.TrimSpace(key)}, nil
+}
+
+// DetectSelf derives the controller's own namespace and workload name from the
+// POD_NAMESPACE/POD_NAME downward-API env vars. Deployment pods are named
+// <deployment>-<pod-template-hash>-<suffix>, so those two trailing segments
+// are stripped to recover the workload name; any other pod name (a
+// StatefulSet or bare pod) is used as is. Either value is empty when unknown.
+func DetectSelf() (namespace, name string) {
+	return os.Getenv("POD_NAMESPACE"), DeploymentName(os.Getenv("POD_NAME"))
+}
+
+// podNameAlphabet is the character set Kubernetes uses for pod-template
+// hashes and generated name suffixes (no vowels or look-alike characters).
+const podNameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+// DeploymentName strips the ReplicaSet pod-template hash and the 5-character
+// random suffix from a Deployment pod name, returning name unchanged when the
+// trailing segments do not look like them.
+func DeploymentName(name string) string {
+	parts := strings.Split(name, "-")
+	if len(parts) < 3 {
+		return name
+	}
+	hash, suffix := parts[len(parts)-2], parts[len(parts)-1]
+	if len(hash) == 0 || len(hash) > 10 || !onlyPodNameChars(hash) || len(suffix) != 5 || !onlyPodNameChars(suffix) {
+		return name
+	}
+	return strings.Join(parts[:len(parts)-2], "-")
+}
+
+func onlyPodNameChars(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune(podNameAlphabet, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseObjectRef splits a "namespace/name" reference. A bare name resolves to
+// defaultNamespace.
+func ParseObjectRef(ref, defaultNamespace string) (namespace, name string) {
+	if ns, n, ok := strings.Cut(ref, "/"); ok {
+		return ns, n
+	}
+	return defaultNamespace, ref
+}
+
+func stringOr(s, fallback string) string {
+	if s != "" {
+		return s
+	}
+	return fallback
+}
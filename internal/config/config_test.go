@@ -0,0 +1,242 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectSelf(t *testing.T) {
+	tests := []struct {
+		pod  string
+		want string
+	}{
+		{pod: "homer-sync-7d9f8c6b5-x2x4q", want: "homer-sync"},
+		{pod: "a-b-5c78f9d4b-qwz2k", want: "a-b"},
+		{pod: "homer-sync-0", want: "homer-sync-0"},
+		{pod: "homer-sync-web-1", want: "homer-sync-web-1"},
+		{pod: "homer-sync", want: "homer-sync"},
+		// Vowels never appear in generated suffixes.
+		{pod: "homer-sync-debug-alpha", want: "homer-sync-debug-alpha"},
+		{pod: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pod, func(t *testing.T) {
+			t.Setenv("POD_NAMESPACE", "tools")
+			t.Setenv("POD_NAME", tt.pod)
+			ns, name := DetectSelf()
+			if ns != "tools" || name != tt.want {
+				t.Errorf("DetectSelf() = %q, %q; want %q, %q", ns, name, "tools", tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLabelTags(t *testing.T) {
+	got, err := ParseLabelTags([]string{"tier=prod=>is-danger", " env = dev => is-info "})
+	want := []LabelTag{{Label: "tier", Value: "prod", TagStyle: "is-danger"}, {Label: "env", Value: "dev", TagStyle: "is-info"}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLabelTags = %+v, %v; want %+v", got, err, want)
+	}
+	for _, bad := range []string{"tier=prod", "tier=>is-danger", "=prod=>is-danger", "tier=prod=>"} {
+		if _, err := ParseLabelTags([]string{bad}); err == nil {
+			t.Errorf("ParseLabelTags(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseSummaryGroup(t *testing.T) {
+	tests := []struct {
+		spec, name string
+		want       SummaryGroup
+		wantErr    bool
+	}{
+		{spec: "", want: SummaryGroup{}},
+		{spec: "all", want: SummaryGroup{Mode: "all", Name: "All Services", Icon: "fas fa-star"}},
+		{spec: "recent:5", want: SummaryGroup{Mode: "recent", Limit: 5, Name: "Recently Added", Icon: "fas fa-star"}},
+		{spec: "recent:3", name: "New", want: SummaryGroup{Mode: "recent", Limit: 3, Name: "New", Icon: "fas fa-star"}},
+		{spec: "recent:0", wantErr: true},
+		{spec: "recent:x", wantErr: true},
+		{spec: "newest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseSummaryGroup(tt.spec, tt.name, "fas fa-star")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSummaryGroup(%q) = %+v, want an error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSummaryGroup(%q) = %+v, %v; want %+v", tt.spec, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseObjectRef(t *testing.T) {
+	tests := []struct{ ref, wantNS, wantName string }{
+		{ref: "ops/banner", wantNS: "ops", wantName: "banner"},
+		{ref: "banner", wantNS: "homer", wantName: "banner"},
+	}
+	for _, tt := range tests {
+		if ns, name := ParseObjectRef(tt.ref, "homer"); ns != tt.wantNS || name != tt.wantName {
+			t.Errorf("ParseObjectRef(%q) = %s, %s; want %s, %s", tt.ref, ns, name, tt.wantNS, tt.wantName)
+		}
+	}
+}
+
+func TestOrderPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OrderPolicy
+		wantErr bool
+	}{
+		{name: "default", policy: DefaultOrderPolicy()},
+		{name: "all keys", policy: OrderPolicy{GroupKeys: GroupOrderKeys, ItemKeys: ItemOrderKeys}},
+		{name: "no group keys", policy: OrderPolicy{ItemKeys: []string{"name"}}, wantErr: true},
+		{name: "no item keys", policy: OrderPolicy{GroupKeys: []string{"name"}}, wantErr: true},
+		{name: "item-only key for groups", policy: OrderPolicy{GroupKeys: []string{"created"}, ItemKeys: []string{"name"}}, wantErr: true},
+		{name: "unknown item key", policy: OrderPolicy{GroupKeys: []string{"name"}, ItemKeys: []string{"size"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseOutputMap(t *testing.T) {
+	got, err := ParseOutputMap("homer-media=Media, TV; homer-ops=Monitoring;")
+	want := []OutputMapping{{Name: "homer-media", Groups: []string{"Media", "TV"}}, {Name: "homer-ops", Groups: []string{"Monitoring"}}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseOutputMap = %+v, %v; want %+v", got, err, want)
+	}
+	for _, bad := range []string{"homer-media", "=Media", "homer-media= , "} {
+		if _, err := ParseOutputMap(bad); err == nil {
+			t.Errorf("ParseOutputMap(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestResolveFilterMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		filters bool
+		want    string
+		wantErr bool
+	}{
+		{name: "inferred opt-in", want: FilterModeOptIn},
+		{name: "inferred opt-out", filters: true, want: FilterModeOptOut},
+		{name: "explicit strict", mode: "strict", filters: true, want: FilterModeStrict},
+		{name: "explicit opt-in with filters", mode: "opt-in", filters: true, want: FilterModeOptIn},
+		{name: "unknown", mode: "loose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if tt.filters {
+				c.DomainSuffixes = []string{".example.com"}
+			}
+			err := c.ResolveFilterMode(tt.mode)
+			if (err != nil) != tt.wantErr || (!tt.wantErr && c.FilterMode != tt.want) {
+				t.Errorf("ResolveFilterMode(%q) = %v with mode %q; want %q", tt.mode, err, c.FilterMode, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResolveSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve bool
+		kind    string
+		wantErr bool
+	}{
+		{name: "off with configmap", kind: "configmap"},
+		{name: "on with secret", resolve: true, kind: "secret"},
+		{name: "on with configmap", resolve: true, kind: "configmap", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{ResolveSecrets: tt.resolve, OutputKind: tt.kind}
+			if err := c.ValidateResolveSecrets(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResolveSecrets() = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    GroupBy
+		wantErr bool
+	}{
+		{spec: "", want: GroupBy{Source: "namespace"}},
+		{spec: "namespace", want: GroupBy{Source: "namespace"}},
+		{spec: "label:team", want: GroupBy{Source: "label", Key: "team"}},
+		{spec: "annotation: example.com/group ", want: GroupBy{Source: "annotation", Key: "example.com/group"}},
+		{spec: "label:", wantErr: true},
+		{spec: "owner:team", wantErr: true},
+		{spec: "label", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseGroupBy(tt.spec)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseGroupBy(%q) = %+v, %v; want %+v", tt.spec, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseURLRewrites(t *testing.T) {
+	rules, err := ParseURLRewrites(`^http://(.*)\.lan$=>https://$1.example.com; ^https://[a-z]{2,}\.old=>https://new`)
+	if err != nil || len(rules) != 2 {
+		t.Fatalf("ParseURLRewrites = %d rules, %v; want 2", len(rules), err)
+	}
+	if got := rules[0].Pattern.ReplaceAllString("http://app.lan", rules[0].Replacement); got != "https://app.example.com" {
+		t.Errorf("first rule rewrites to %q", got)
+	}
+	if rules[1].Pattern.String() != `^https://[a-z]{2,}\.old` {
+		t.Errorf("second rule pattern = %q; commas must not split rules", rules[1].Pattern)
+	}
+	for _, bad := range []string{"no-arrow", "=>https://x", "([=>x"} {
+		if _, err := ParseURLRewrites(bad); err == nil {
+			t.Errorf("ParseURLRewrites(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	got, err := ParseKeyValues("configmap-labels", []string{"app=homer", " team = ops ", "empty="})
+	want := map[string]string{"app": "homer", "team": "ops", "empty": ""}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseKeyValues = %v, %v; want %v", got, err, want)
+	}
+	if got, err := ParseKeyValues("configmap-labels", nil); got != nil || err != nil {
+		t.Errorf("ParseKeyValues(nil) = %v, %v; want nil", got, err)
+	}
+	for _, bad := range []string{"app", "=homer"} {
+		if _, err := ParseKeyValues("configmap-labels", []string{bad}); err == nil {
+			t.Errorf("ParseKeyValues(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseDomainGroups(t *testing.T) {
+	got, err := ParseDomainGroups([]string{"*.media.example.com=Media", " .lan = Home "})
+	want := []DomainGroup{{Suffix: "*.media.example.com", Group: "Media"}, {Suffix: ".lan", Group: "Home"}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDomainGroups = %+v, %v; want %+v", got, err, want)
+	}
+	for _, bad := range []string{".lan", "=Home", ".lan="} {
+		if _, err := ParseDomainGroups([]string{bad}); err == nil {
+			t.Errorf("ParseDomainGroups(%q) succeeded", bad)
+		}
+	}
+}
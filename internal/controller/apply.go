@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// fieldManager identifies homer-sync's server-side apply ownership.
+const fieldManager = "homer-sync"
+
+// ownerApplyConfig returns the owner reference to include in an apply, or nil.
+// Unlike update mode it is sent on every apply: a field homer-sync stops
+// applying would be removed from the object.
+func (c *Controller) ownerApplyConfig(ctx context.Context) (*metav1ac.OwnerReferenceApplyConfiguration, error) {
+	owner, err := c.ownerReference(ctx)
+	if err != nil || owner == nil {
+		return nil, err
+	}
+	return metav1ac.OwnerReference().
+		WithAPIVersion(owner.APIVersion).
+		WithKind(owner.Kind).
+		WithName(owner.Name).
+		WithUID(owner.UID), nil
+}
+
+// applyConfigMap writes rendered with server-side apply, forcing ownership of
+// the data key (or, when gz is set, of the gzipped binaryData key instead).
+// existing is the current object, or nil when it does not exist; an unchanged
+// object is left alone so its resourceVersion does not churn.
+func (c *Controller) applyConfigMap(ctx context.Context, name, rendered string, gz []byte, existing *corev1.ConfigMap) (bool, error) {
+	ns := c.cfg.ConfigMapNamespace
+	key := c.cfg.ConfigMapKey
+
+	if existing != nil {
+		live, compressed := storedConfig(existing, key)
+		if compressed == (gz != nil) && c.upToDate("configmap", ns, name, existing.ObjectMeta, live, rendered) {
+			syncLog().Debug("configmap already up to date", "namespace", ns, "name", name)
+			c.recordEvent(existing, corev1.EventTypeNormal, reasonUnchanged, "Homer config already up to date")
+			return false, nil
+		}
+	}
+
+	ac := corev1ac.ConfigMap(name, ns).
+		WithLabels(c.cfg.OutputLabels).
+		WithAnnotations(c.outputAnnotations(contentHash(rendered)))
+	if gz != nil {
+		ac.WithBinaryData(map[string][]byte{gzipKey(key): gz})
+	} else {
+		ac.WithData(map[string]string{key: rendered})
+	}
+	owner, err := c.ownerApplyConfig(ctx)
+	if err != nil {
+		return false, fmt.Errorf("resolve owner reference: %w", err)
+	}
+	if owner != nil {
+		ac.WithOwnerReferences(owner)
+	}
+
+	applyCtx, cancel := c.apiContext(ctx)
+	defer cancel()
+	applied, err := c.output().CoreV1().ConfigMaps(ns).Apply(applyCtx, ac, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		return false, fmt.Errorf("apply configmap %s/%s: %w", ns, name, err)
+	}
+	c.logApplied("configmap", ns, name, existing == nil, applied)
+	return true, nil
+}
+
+// applySecret is the Secret counterpart of applyConfigMap.
+func (c *Controller) applySecret(ctx context.Context, name, rendered string, existing *corev1.Secret) (bool, error) {
+	ns := c.cfg.ConfigMapNamespace
+	key := c.cfg.ConfigMapKey
+
+	if existing != nil && c.upToDate("secret", ns, name, existing.ObjectMeta, string(existing.Data[key]), rendered) {
+		syncLog().Debug("secret already up to date", "namespace", ns, "name", name)
+		c.recordEvent(existing, corev1.EventTypeNormal, reasonUnchanged, "Homer config already up to date")
+		return false, nil
+	}
+
+	ac := corev1ac.Secret(name, ns).
+		WithLabels(c.cfg.OutputLabels).
+		WithAnnotations(c.outputAnnotations(contentHash(rendered))).
+		WithType(corev1.SecretTypeOpaque).
+		WithData(map[string][]byte{key: []byte(rendered)})
+	owner, err := c.ownerApplyConfig(ctx)
+	if err != nil {
+		return false, fmt.Errorf("resolve owner reference: %w", err)
+	}
+	if owner != nil {
+		ac.WithOwnerReferences(owner)
+	}
+
+	applyCtx, cancel := c.apiContext(ctx)
+	defer cancel()
+	applied, err := c.output().CoreV1().Secrets(ns).Apply(applyCtx, ac, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		return false, fmt.Errorf("apply secret %s/%s: %w", ns, name, err)
+	}
+	c.logApplied("secret", ns, name, existing == nil, applied)
+	return true, nil
+}
+
+// logApplied logs and records the outcome of a successful apply.
+func (c *Controller) logApplied(kind, ns, name string, created bool, obj runtime.Object) {
+	if created {
+		syncLog().Info("created "+kind, "namespace", ns, "name", name, "mode", "ssa")
+		c.recordEvent(obj, corev1.EventTypeNormal, reasonCreated, "Created Homer config")
+		return
+	}
+	syncLog().Info("updated "+kind, "namespace", ns, "name", name, "mode", "ssa")
+	c.recordEvent(obj, corev1.EventTypeNormal, reasonUpdated, "Updated Homer config")
+}
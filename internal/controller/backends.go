@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// backendServiceRefs returns the "namespace/name" of every core Service a
+// route's rules forward to, in rule order and without duplicates. Refs to
+// other kinds are ignored; a ref without a namespace targets the route's own.
+func backendServiceRefs(refs []gwv1.BackendObjectReference, routeNamespace string) []string {
+	out := make([]string, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, br := range refs {
+		if br.Group != nil && *br.Group != "" {
+			continue
+		}
+		if br.Kind != nil && *br.Kind != "Service" {
+			continue
+		}
+		ns := routeNamespace
+		if br.Namespace != nil {
+			ns = string(*br.Namespace)
+		}
+		key := ns + "/" + string(br.Name)
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// fetchServiceAnnotations lists Services cluster-wide once per scan and
+// returns their home.mirceanton.com/* and --subtitle-fallback annotations
+// keyed by "namespace/name".
+// Services without any such annotation are omitted.
+func (c *Controller) fetchServiceAnnotations(ctx context.Context) (map[string]map[string]string, error) {
+	list, err := listWithRetry(ctx, c, "services", func(ctx context.Context) (*corev1.ServiceList, error) {
+		return c.clients.Core.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+
+	out := make(map[string]map[string]string)
+	for _, svc := range list.Items {
+		for k, v := range svc.Annotations {
+			if !strings.HasPrefix(k, config.AnnotationPrefix+"/") && !slices.Contains(c.cfg.SubtitleFallback, k) {
+				continue
+			}
+			key := svc.Namespace + "/" + svc.Name
+			if out[key] == nil {
+				out[key] = make(map[string]string)
+			}
+			out[key][k] = v
+		}
+	}
+	return out, nil
+}
+
+// mergeBackendAnnotations returns a copy of route whose annotations are
+// layered over those of its backend Services. Route annotations always win;
+// between Services, the first backendRef to set a key wins.
+func mergeBackendAnnotations(route map[string]interface{}, svcAnn map[string]map[string]string) map[string]interface{} {
+	refs, _ := route["backendRefs"].([]string)
+	if len(refs) == 0 {
+		return route
+	}
+
+	merged := make(map[string]string)
+	for _, ref := range refs {
+		for k, v := range svcAnn[ref] {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return route
+	}
+	for k, v := range routeAnnotations(route) {
+		merged[k] = v
+	}
+
+	out := make(map[string]interface{}, len(route))
+	for k, v := range route {
+		out[k] = v
+	}
+	out["annotations"] = merged
+	return out
+}
+
+func httpBackendRefs(rules []gwv1.HTTPRouteRule) []gwv1.BackendObjectReference {
+	var out []gwv1.BackendObjectReference
+	for _, rule := range rules {
+		for _, br := range rule.BackendRefs {
+			out = append(out, br.BackendObjectReference)
+		}
+	}
+	return out
+}
+
+func grpcBackendRefs(rules []gwv1.GRPCRouteRule) []gwv1.BackendObjectReference {
+	var out []gwv1.BackendObjectReference
+	for _, rule := range rules {
+		for _, br := range rule.BackendRefs {
+			out = append(out, br.BackendObjectReference)
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// TestBackendAnnotationsFilter pins that backend Service annotations are
+// merged before filtering: enabled on the Service decides inclusion unless
+// the route sets its own, and wins over the namespace's.
+func TestBackendAnnotationsFilter(t *testing.T) {
+	enabled := config.AnnotationPrefix + "/enabled"
+	tests := []struct {
+		name     string
+		routeAnn string // "" leaves the route without enabled
+		svcAnn   string
+		nsAnn    string
+		want     bool
+	}{
+		{name: "service opts in", svcAnn: "true", want: true},
+		{name: "route overrides service", routeAnn: "false", svcAnn: "true", want: false},
+		{name: "service overrides namespace", svcAnn: "false", nsAnn: "true", want: false},
+		{name: "namespace without service annotation", nsAnn: "true", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.BackendAnnotations = true
+			if err := cfg.ResolveFilterMode(""); err != nil {
+				t.Fatal(err)
+			}
+
+			nsAnn := map[string]string{}
+			if tt.nsAnn != "" {
+				nsAnn[enabled] = tt.nsAnn
+			}
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "media", Name: "plex", Annotations: map[string]string{}}}
+			if tt.svcAnn != "" {
+				svc.Annotations[enabled] = tt.svcAnn
+			}
+			route := testRoute("media", "plex", nil, "plex.example.com")
+			delete(route.Annotations, enabled)
+			if tt.routeAnn != "" {
+				route.Annotations[enabled] = tt.routeAnn
+			}
+			route.Spec.Rules = []gwv1.HTTPRouteRule{{BackendRefs: []gwv1.HTTPBackendRef{{
+				BackendRef: gwv1.BackendRef{BackendObjectReference: gwv1.BackendObjectReference{Name: "plex"}},
+			}}}}
+
+			c, cs := newTestController(cfg, []runtime.Object{testNamespace("media", nsAnn), svc}, route)
+			if _, err := c.runOnce(context.Background()); err != nil {
+				t.Fatalf("runOnce: %v", err)
+			}
+			if got := strings.Contains(outputConfig(t, cs, cfg), "plex.example.com"); got != tt.want {
+				t.Errorf("route included = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
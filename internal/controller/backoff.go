@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// maxBackoffFactor caps the daemon retry delay at this multiple of the scan
+// interval.
+const maxBackoffFactor = 10
+
+// backoff computes the delay before the next daemon scan. After a success it
+// is the plain scan interval; each consecutive failure doubles it, up to
+// maxBackoffFactor times the interval, with jitter so replicas and restarts do
+// not retry in lockstep.
+type backoff struct {
+	interval time.Duration
+	failures int
+}
+
+// next records the outcome of a scan and returns how long to wait before the
+// following one.
+func (b *backoff) next(failed bool) time.Duration {
+	if !failed {
+		b.failures = 0
+		return b.interval
+	}
+	b.failures++
+
+	limit := maxBackoffFactor * b.interval
+	delay := b.interval
+	for i := 0; i < b.failures && delay < limit; i++ {
+		delay *= 2
+	}
+	delay = min(delay, limit)
+
+	// Full delay minus up to half of it, so the wait never drops below half
+	// the computed backoff.
+	if half := int64(delay / 2); half > 0 {
+		delay -= time.Duration(rand.Int64N(half))
+	}
+	return delay
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	b := &backoff{interval: time.Minute}
+	for failures, full := range []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+		got := b.next(true)
+		if got < full/2 || got > full {
+			t.Errorf("failure %d: delay %s, want within [%s, %s]", failures+1, got, full/2, full)
+		}
+	}
+	if got := b.next(false); got != time.Minute {
+		t.Errorf("delay after success = %s, want the scan interval", got)
+	}
+	if b.failures != 0 {
+		t.Errorf("failures = %d after success, want 0", b.failures)
+	}
+}
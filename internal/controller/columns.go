@@ -0,0 +1,62 @@
+package controller
+
+// layoutColumns arranges a group's items into cols explicit columns when at
+// least one item is pinned via home.mirceanton.com/column; otherwise it returns
+// nil and the group keeps its flat item list. Pinned items land in their
+// (1-based) column, clamped to the column count; unpinned items then fill the
+// shortest column, leftmost first, preserving sort order.
+func layoutColumns(items []ServiceItem, cols int) [][]ServiceItem {
+	if cols <= 0 {
+		return nil
+	}
+	pinned := false
+	for _, it := range items {
+		if it.Column > 0 {
+			pinned = true
+			break
+		}
+	}
+	if !pinned {
+		return nil
+	}
+
+	layout := make([][]ServiceItem, cols)
+	for _, it := range items {
+		if it.Column > 0 {
+			col := min(it.Column, cols) - 1
+			layout[col] = append(layout[col], it)
+		}
+	}
+	for _, it := range items {
+		if it.Column > 0 {
+			continue
+		}
+		shortest := 0
+		for i := range layout {
+			if len(layout[i]) < len(layout[shortest]) {
+				shortest = i
+			}
+		}
+		layout[shortest] = append(layout[shortest], it)
+	}
+	return layout
+}
+
+// rowMajor flattens a column layout back into Homer's row-by-row item order,
+// so templates that only iterate Items still place pinned items in their
+// column wherever the columns are evenly filled.
+func rowMajor(layout [][]ServiceItem) []ServiceItem {
+	var out []ServiceItem
+	for row := 0; ; row++ {
+		added := false
+		for _, col := range layout {
+			if row < len(col) {
+				out = append(out, col[row])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// objectSizeLimit is the largest ConfigMap or Secret the API server accepts.
+const objectSizeLimit = 1 << 20
+
+// gzipKey is the binaryData key a --compress ConfigMap stores the gzipped
+// config under.
+func gzipKey(key string) string {
+	return key + ".gz"
+}
+
+// shouldCompress reports whether rendered is stored gzipped under gzipKey
+// instead of as plain data: only with --compress and once it outgrows
+// --compress-threshold.
+func (c *Controller) shouldCompress(rendered string) bool {
+	return c.cfg.Compress && len(rendered) > c.cfg.CompressThreshold
+}
+
+// checkSize fails with an actionable error when a payload of n bytes would
+// push the output object over the API server's size limit, instead of leaving
+// the write to be rejected.
+func checkSize(kind, ns, name string, n int, compressed bool) error {
+	if n <= objectSizeLimit {
+		return nil
+	}
+	hint := "split the dashboard with --configmap-map"
+	if kind == "configmap" && !compressed {
+		hint = "enable --compress or " + hint
+	}
+	return fmt.Errorf("%s %s/%s: config is %d bytes, over the %d byte object size limit; %s", kind, ns, name, n, objectSizeLimit, hint)
+}
+
+func gzipString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, s); err != nil {
+		return nil, fmt.Errorf("compress config: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// storedConfig returns the config cm currently holds under key, gunzipping
+// the binaryData copy when there is one, and whether that copy was used.
+func storedConfig(cm *corev1.ConfigMap, key string) (string, bool) {
+	gz, ok := cm.BinaryData[gzipKey(key)]
+	if !ok {
+		return cm.Data[key], false
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return "", true
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return "", true
+	}
+	return string(raw), true
+}
+
+// encodeConfig returns rendered gzipped when it should be compressed, or nil
+// when it is stored as plain data, after checking that the stored form fits.
+func (c *Controller) encodeConfig(ns, name, rendered string) ([]byte, error) {
+	if !c.shouldCompress(rendered) {
+		return nil, checkSize("configmap", ns, name, len(rendered), false)
+	}
+	gz, err := gzipString(rendered)
+	if err != nil {
+		return nil, err
+	}
+	return gz, checkSize("configmap", ns, name, len(gz), true)
+}
+
+// configMapPatchFields returns the data and binaryData merge patch fields
+// storing rendered (or its gzipped form gz) and removing the other form.
+func configMapPatchFields(key, rendered string, gz []byte) map[string]interface{} {
+	if gz != nil {
+		return map[string]interface{}{
+			"data":       map[string]interface{}{key: nil},
+			"binaryData": map[string]interface{}{gzipKey(key): gz},
+		}
+	}
+	return map[string]interface{}{
+		"data":       map[string]interface{}{key: rendered},
+		"binaryData": map[string]interface{}{gzipKey(key): nil},
+	}
+}
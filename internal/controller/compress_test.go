@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestEncodeConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.Compress = true
+	cfg.CompressThreshold = 16
+	c := &Controller{cfg: cfg}
+
+	if gz, err := c.encodeConfig("default", "homer-config", "small"); gz != nil || err != nil {
+		t.Errorf("encodeConfig below the threshold = %v, %v; want plain data", gz, err)
+	}
+
+	rendered := strings.Repeat("services: []\n", 100)
+	gz, err := c.encodeConfig("default", "homer-config", rendered)
+	if err != nil || gz == nil {
+		t.Fatalf("encodeConfig = %v, %v; want gzipped data", gz, err)
+	}
+	cm := &corev1.ConfigMap{BinaryData: map[string][]byte{gzipKey(cfg.ConfigMapKey): gz}}
+	if got, compressed := storedConfig(cm, cfg.ConfigMapKey); got != rendered || !compressed {
+		t.Errorf("storedConfig did not round-trip the compressed config (compressed=%v)", compressed)
+	}
+}
+
+func TestCheckSize(t *testing.T) {
+	if err := checkSize("configmap", "default", "homer-config", objectSizeLimit, false); err != nil {
+		t.Errorf("checkSize at the limit: %v", err)
+	}
+	err := checkSize("configmap", "default", "homer-config", objectSizeLimit+1, false)
+	if err == nil || !strings.Contains(err.Error(), "--compress") {
+		t.Errorf("checkSize over the limit = %v, want a hint to enable --compress", err)
+	}
+	err = checkSize("configmap", "default", "homer-config", objectSizeLimit+1, true)
+	if err == nil || strings.Contains(err.Error(), "--compress") {
+		t.Errorf("checkSize over the limit when compressed = %v, want only the --configmap-map hint", err)
+	}
+}
+
+// TestCompressSwitchesStorage checks that a sync stores the config in exactly
+// one form, moving it between data and binaryData as it crosses the
+// threshold.
+func TestCompressSwitchesStorage(t *testing.T) {
+	cfg := testConfig()
+	cfg.Compress = true
+	cfg.CompressThreshold = 1
+	core := []runtime.Object{testNamespace("media", nil)}
+	c, cs := newTestController(cfg, core, testRoute("media", "jellyfin", nil, "jellyfin.example.com"))
+
+	get := func() *corev1.ConfigMap {
+		cm, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), cfg.ConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cm
+	}
+
+	if _, err := c.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+	cm := get()
+	if _, plain := cm.Data[cfg.ConfigMapKey]; plain || cm.BinaryData[gzipKey(cfg.ConfigMapKey)] == nil {
+		t.Fatalf("compressed sync stored data keys %v and binaryData keys %v", slices.Sorted(maps.Keys(cm.Data)), slices.Sorted(maps.Keys(cm.BinaryData)))
+	}
+
+	cfg.CompressThreshold = 1 << 20
+	if _, err := c.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+	cm = get()
+	if _, gz := cm.BinaryData[gzipKey(cfg.ConfigMapKey)]; gz || !strings.Contains(cm.Data[cfg.ConfigMapKey], "jellyfin") {
+		t.Errorf("plain sync stored data keys %v and binaryData keys %v", slices.Sorted(maps.Keys(cm.Data)), slices.Sorted(maps.Keys(cm.BinaryData)))
+	}
+}
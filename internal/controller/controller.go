@@ -1,17 +1,29 @@
 package controller
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
-	"log/slog"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/mirceanton/homer-sync/internal/config"
 	"github.com/mirceanton/homer-sync/internal/k8s"
@@ -19,116 +31,504 @@ import (
 
 // ServiceItem holds the resolved metadata for a single Homer dashboard entry.
 type ServiceItem struct {
+	// Namespace and Route identify the source object the item came from.
+	Namespace string
+	Route     string
 	Name      string
 	Subtitle  string
 	URL       string
 	Icon      string
+	// Logo is a full logo URL, set by --auto-icon when no icon annotation is
+	// present; it takes precedence over Icon in the built-in template.
+	Logo      string
 	Group     string
 	GroupIcon string
 	Sort      int
+	SortKey   string
+	// Column pins the item to a 1-based column of its group; 0 means unpinned.
+	Column   int
+	Tag      string
+	TagStyle string
+	// Keywords are extra search terms for Homer's find-as-you-type.
+	Keywords []string
+	Created  time.Time
+	Type     string
+	Endpoint string
+	// Hidden items stay in the model (counted and logged) but get no card.
+	Hidden bool
+	// Unsearchable renders searchable: false, without keywords, to keep the
+	// item out of Homer's search.
+	Unsearchable bool
+	// APIKey is the smart card API key, read from the Secret key APIKeyRef
+	// names with --resolve-secrets.
+	APIKey    string
+	APIKeyRef SecretKeyRef
+	// Dashboards restricts the item to these output ConfigMaps; empty means
+	// every output its group is written to.
+	Dashboards []string
+	// Class and Background style the tile (a CSS class and a CSS
+	// background value).
+	Class      string
+	Background string
+	// Environment is the namespace part removed by
+	// --strip-namespace-prefix/--strip-namespace-suffix (e.g. "prod").
+	Environment string
+	// Target is the link target (e.g. _blank); empty omits it.
+	Target string
+	// Headers are sent with the health check request, e.g. for auth.
+	Headers HealthHeaders
+	// ProbeURL is the address health checks poll, which may differ from the
+	// link (e.g. an in-cluster Service URL); it defaults to URL.
+	ProbeURL string
+}
+
+// HealthHeaders are HTTP headers for a health check. They often carry
+// credentials, so String redacts the values and a logged item never shows
+// them.
+type HealthHeaders map[string]string
+
+func (h HealthHeaders) String() string {
+	fields := make([]string, 0, len(h))
+	for k := range h {
+		fields = append(fields, k+":<redacted>")
+	}
+	sort.Strings(fields)
+	return "map[" + strings.Join(fields, " ") + "]"
+}
+
+// ScanSummary counts what a single scan did with the routes it saw.
+type ScanSummary struct {
+	// Included is the number of dashboard items built, Hidden among them,
+	// spread over Groups groups.
+	Included int
+	Hidden   int
+	Groups   int
+	// Skipped counts routes that passed the filters but were dropped as
+	// unusable, e.g. for lacking a hostname.
+	Skipped int
+	// RenderErrors counts failed renders, including ones recovered by falling
+	// back to the last good template.
+	RenderErrors int
+	// Routes is the number of routes listed, Filtered those rejected by the
+	// namespace, gateway, domain and enablement filters or disabled groups.
+	Routes   int
+	Filtered int
+	// Changed reports whether any output was written.
+	Changed bool
 }
 
 // Controller performs the scan→render→sync cycle.
 type Controller struct {
 	clients *k8s.Clients
 	cfg     *config.Config
+
+	recorder record.EventRecorder
+
+	mu                 sync.Mutex
+	lastSuccessfulSync time.Time
+	lastSummary        ScanSummary
+	owner              *metav1.OwnerReference
+
+	// lastGoodTemplate is the last --template-path or --template-dir source
+	// that rendered cleanly; a broken edit falls back to it instead of failing
+	// the scan.
+	lastGoodTemplate templateSource
+
+	// secretVersions maps each Secret the last scan resolved ("ns/name") to
+	// the resource version read; secretWatches cancels the watch of each
+	// namespace holding one.
+	secretVersions map[string]string
+	secretWatches  map[string]context.CancelFunc
+
+	// nsCache is the namespace map listed at nsCacheTime, reused for
+	// --namespace-cache-ttl.
+	nsCache     map[string]namespaceMeta
+	nsCacheTime time.Time
 }
 
 // New returns a Controller ready to run.
 func New(clients *k8s.Clients, cfg *config.Config) *Controller {
-	return &Controller{clients: clients, cfg: cfg}
+	c := &Controller{clients: clients, cfg: cfg}
+	if cfg.EmitEvents {
+		c.recorder = k8s.NewEventRecorder(c.output())
+	}
+	return c
+}
+
+// output returns the client for the cluster the rendered config is written
+// to, which is the source cluster unless an output cluster is configured.
+func (c *Controller) output() kubernetes.Interface {
+	if c.clients.Output != nil {
+		return c.clients.Output
+	}
+	return c.clients.Core
+}
+
+// Ready reports whether a scan has succeeded recently enough. In daemon mode a
+// success older than two scan intervals counts as stale.
+func (c *Controller) Ready() bool {
+	c.mu.Lock()
+	last := c.lastSuccessfulSync
+	c.mu.Unlock()
+
+	if last.IsZero() {
+		return false
+	}
+	if !c.cfg.Daemon {
+		return true
+	}
+	return time.Since(last) <= 2*time.Duration(c.cfg.ScanInterval)*time.Second
+}
+
+// LastSummary returns the summary of the most recent one-shot scan attempt.
+func (c *Controller) LastSummary() ScanSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastSummary
+}
+
+// apiContext bounds a single Kubernetes API call by --api-timeout so a hung
+// API server surfaces as an error instead of stalling the scan. A zero timeout
+// leaves ctx unbounded.
+func (c *Controller) apiContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.cfg.APITimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(c.cfg.APITimeout)*time.Second)
 }
 
 // Run starts the controller. In daemon mode it loops indefinitely; otherwise it
 // runs once and returns.
 func (c *Controller) Run(ctx context.Context) error {
+	if err := c.preflight(ctx); err != nil {
+		if !c.cfg.Daemon || c.cfg.FailOnInitialSync {
+			return fmt.Errorf("preflight: %w", err)
+		}
+		// The API may still be installed later; keep scanning.
+		if !logMissingAPI(err) {
+			ctrlLog().Warn("preflight check failed", "error", err)
+		}
+	}
+
 	if c.cfg.Daemon {
-		for {
-			if err := ctx.Err(); err != nil {
-				return nil
+		// A nil channel never fires, so without template files or Secret
+		// references only the interval drives scans.
+		var reload chan struct{}
+		if c.templateFromFiles() || c.cfg.ResolveSecrets {
+			reload = make(chan struct{}, 1)
+		}
+		if c.templateFromFiles() {
+			path := c.templateWatchPath()
+			if err := watchTemplate(ctx, path, c.cfg.TemplatePath == "", reload); err != nil {
+				ctrlLog().Warn("cannot watch custom template; edits apply on the next scan", "path", path, "error", err)
 			}
-			if err := c.runOnce(ctx); err != nil {
-				slog.Error("unhandled error during scan; will retry after interval", "error", err)
+		}
+
+		// The first scan runs before the loop so --fail-on-initial-sync can
+		// surface its error instead of leaving it to the backoff.
+		sum, err := c.runOnce(ctx)
+		c.reportStatus(ctx, sum, err)
+		if err != nil && c.cfg.FailOnInitialSync {
+			return fmt.Errorf("initial sync: %w", err)
+		}
+
+		bo := backoff{interval: time.Duration(c.cfg.ScanInterval) * time.Second}
+		for {
+			delay := bo.next(err != nil)
+			if err != nil {
+				logMissingAPI(err)
+				ctrlLog().Error("unhandled error during scan; backing off", "error", err, "failures", bo.failures, "delay", delay)
 			}
+			c.updateSecretWatches(ctx, reload)
 			select {
 			case <-ctx.Done():
+				c.finalSync(ctx)
 				return nil
-			case <-time.After(time.Duration(c.cfg.ScanInterval) * time.Second):
+			case <-time.After(delay):
+			case <-reload:
 			}
+			sum, err = c.runOnce(ctx)
+			c.reportStatus(ctx, sum, err)
+		}
+	}
+	return c.runOneShot(ctx)
+}
+
+// finalSync runs one last scan once ctx is cancelled (SIGTERM) when
+// --sync-on-shutdown is set, so a change made just before shutdown still
+// reaches the dashboard. It is bounded by --shutdown-timeout.
+func (c *Controller) finalSync(ctx context.Context) {
+	if !c.cfg.SyncOnShutdown {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	if c.cfg.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.cfg.ShutdownTimeout)*time.Second)
+		defer cancel()
+	}
+
+	ctrlLog().Info("running final sync before shutdown", "timeout", c.cfg.ShutdownTimeout)
+	sum, err := c.runOnce(ctx)
+	c.reportStatus(ctx, sum, err)
+	if err != nil {
+		ctrlLog().Error("final sync failed", "error", err)
+	}
+}
+
+// runOneShot performs a single sync bounded by OnceTimeout, retrying failed
+// scans up to OnceRetries times with exponential backoff.
+func (c *Controller) runOneShot(ctx context.Context) error {
+	if c.cfg.OnceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.cfg.OnceTimeout)*time.Second)
+		defer cancel()
+	}
+
+	delay := time.Second
+	for attempt := 0; ; attempt++ {
+		sum, err := c.runOnce(ctx)
+		c.reportStatus(ctx, sum, err)
+		c.mu.Lock()
+		c.lastSummary = sum
+		c.mu.Unlock()
+		if err == nil || attempt >= c.cfg.OnceRetries {
+			return err
+		}
+		ctrlLog().Warn("scan failed; retrying", "error", err, "attempt", attempt+1, "retries", c.cfg.OnceRetries, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		case <-time.After(delay):
 		}
+		delay = min(delay*2, 30*time.Second)
 	}
-	return c.runOnce(ctx)
 }
 
 // ---------------------------------------------------------------------------
 // Single scan cycle
 // ---------------------------------------------------------------------------
 
-func (c *Controller) runOnce(ctx context.Context) error {
-	slog.Info("starting scan")
+// renderedOutput is the config rendered for one output target: a single
+// config, or one per shard with --auto-shard.
+type renderedOutput struct {
+	name    string
+	configs []string
+}
 
+// scanResult is what a scan found: the dashboard items, hidden ones
+// included, and the namespaces they were resolved against.
+type scanResult struct {
+	items []ServiceItem
+	nsMap map[string]namespaceMeta
+	// nsNames holds the keys of nsMap sorted once per scan, for the lookups
+	// where the first namespace by name wins.
+	nsNames []string
+}
+
+// scan lists the cluster and returns the dashboard items and namespaces,
+// filling in the counts of sum.
+func (c *Controller) scan(ctx context.Context, sum *ScanSummary) (*scanResult, error) {
 	nsMap, err := c.fetchNamespaces(ctx)
 	if err != nil {
-		return fmt.Errorf("fetch namespaces: %w", err)
+		return nil, fmt.Errorf("fetch namespaces: %w", err)
 	}
+	nsNames := slices.Sorted(maps.Keys(nsMap))
 
-	routes, err := c.fetchHTTPRoutes(ctx)
+	routes, err := c.fetchRoutes(ctx)
 	if err != nil {
-		return fmt.Errorf("fetch httproutes: %w", err)
+		return nil, err
 	}
-	slog.Debug("found httproutes", "count", len(routes))
-
-	groupIconCache := make(map[string]string)
-	var items []ServiceItem
 
-	for _, route := range routes {
-		if !c.shouldInclude(route) {
-			continue
+	var svcAnn map[string]map[string]string
+	if c.cfg.BackendAnnotations {
+		if svcAnn, err = c.fetchServiceAnnotations(ctx); err != nil {
+			return nil, fmt.Errorf("fetch backend services: %w", err)
 		}
-		item, ok := c.extractItem(route, nsMap, groupIconCache)
-		if ok {
-			items = append(items, item)
+	}
+
+	items, counts := c.collectItems(routes, nsMap, nsNames, svcAnn)
+	skipped := counts.noHostname
+	sum.Routes, sum.Filtered = len(routes), counts.filtered
+
+	if c.cfg.Dedupe {
+		items = c.dedupeItems(items)
+	}
+	if c.cfg.ResolveSecrets {
+		c.resolveSecrets(ctx, items)
+	}
+
+	groups := make(map[string]bool)
+	hidden := 0
+	for _, item := range items {
+		groups[item.Group] = true
+		if item.Hidden {
+			hidden++
 		}
 	}
+	sum.Included, sum.Hidden, sum.Skipped, sum.Groups = len(items), hidden, skipped, len(groups)
+	ctrlLog().Info("collected services", "services", len(items), "hidden", hidden, "skipped", skipped, "groups", len(groups))
+	return &scanResult{items: items, nsMap: nsMap, nsNames: nsNames}, nil
+}
 
+// render scans the cluster and renders the config of every output target
+// without writing any of them, filling in sum as it goes.
+func (c *Controller) render(ctx context.Context, sum *ScanSummary) ([]renderedOutput, error) {
+	res, err := c.scan(ctx, sum)
+	if err != nil {
+		return nil, err
+	}
 	groups := make(map[string][]ServiceItem)
-	for _, item := range items {
+	for _, item := range res.items {
 		groups[item.Group] = append(groups[item.Group], item)
 	}
-	for g := range groups {
-		sort.Slice(groups[g], func(i, j int) bool {
-			if groups[g][i].Sort != groups[g][j].Sort {
-				return groups[g][i].Sort < groups[g][j].Sort
+
+	message, err := c.fetchMaintenanceMessage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch maintenance message: %w", err)
+	}
+	if message == nil {
+		message = c.staticMessage()
+	}
+
+	// Render every output before writing any, so a template error never
+	// leaves the dashboards half-updated.
+	outputs := c.splitOutputs(groups)
+	renderWith := func(src templateSource) ([][]string, error) {
+		rendered := make([][]string, len(outputs))
+		for i, out := range outputs {
+			var err error
+			rendered[i], err = c.renderOutput(out, func(groups map[string][]ServiceItem, shard int) (string, error) {
+				return c.buildTemplateData(groups, res.nsMap, res.nsNames, message, src, shard)
+			})
+			if err != nil {
+				sum.RenderErrors++
+				c.recordEvent(c.outputRef(out.name), corev1.EventTypeWarning, reasonRenderFailed, "Rendering Homer config failed: %v", err)
+				return nil, fmt.Errorf("render config for %s: %w", out.name, err)
 			}
-			return groups[g][i].Name < groups[g][j].Name
-		})
+		}
+		return rendered, nil
+	}
+
+	var rendered [][]string
+	tmplSrc, err := c.loadTemplate(ctx)
+	if err != nil {
+		err = fmt.Errorf("load template: %w", err)
+	} else {
+		rendered, err = renderWith(tmplSrc)
+	}
+	if err != nil && c.templateFromFiles() && len(c.lastGoodTemplate.Files) > 0 && !tmplSrc.equal(c.lastGoodTemplate) {
+		ctrlLog().Warn("custom template unusable; keeping last good template", "path", c.templateWatchPath(), "error", err)
+		tmplSrc = c.lastGoodTemplate
+		rendered, err = renderWith(tmplSrc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.templateFromFiles() {
+		c.lastGoodTemplate = tmplSrc
+	}
+
+	out := make([]renderedOutput, len(outputs))
+	for i, o := range outputs {
+		out[i] = renderedOutput{name: o.name, configs: rendered[i]}
+	}
+	return out, nil
+}
+
+// Render runs a single scan and returns the rendered config of every output
+// object keyed by name, with shards after the first under their numbered
+// names. Unlike a sync it never writes the ConfigMaps or Secrets, so it suits
+// embedding homer-sync in another controller; a render failure is still
+// recorded as an Event with --emit-events.
+func (c *Controller) Render(ctx context.Context) (map[string]string, error) {
+	var sum ScanSummary
+	outputs, err := c.render(ctx, &sum)
+	if err != nil {
+		return nil, err
 	}
+	configs := make(map[string]string)
+	for _, out := range outputs {
+		for j, config := range out.configs {
+			configs[shardName(out.name, j)] = config
+		}
+	}
+	return configs, nil
+}
 
-	slog.Info("collected services", "services", len(items), "groups", len(groups))
+func (c *Controller) runOnce(ctx context.Context) (ScanSummary, error) {
+	var sum ScanSummary
+	start := time.Now()
+	ctrlLog().Info("starting scan")
 
-	rendered, err := c.buildTemplateData(groups)
+	outputs, err := c.render(ctx, &sum)
 	if err != nil {
-		return fmt.Errorf("render config: %w", err)
+		return sum, err
 	}
 
-	if err := c.syncConfigMap(ctx, rendered); err != nil {
-		return fmt.Errorf("sync configmap: %w", err)
+	for _, out := range outputs {
+		configs := out.configs
+		for j, config := range configs {
+			name := shardName(out.name, j)
+			changed, err := c.syncConfigMap(ctx, name, config)
+			if err != nil {
+				return sum, fmt.Errorf("sync configmap %s: %w", name, err)
+			}
+			sum.Changed = sum.Changed || changed
+		}
+		if c.cfg.AutoShard {
+			if err := c.pruneShards(ctx, out.name, len(configs)); err != nil {
+				return sum, err
+			}
+		}
 	}
 
-	slog.Info("scan complete")
-	return nil
+	c.mu.Lock()
+	c.lastSuccessfulSync = time.Now()
+	c.mu.Unlock()
+
+	ctrlLog().Info("scan summary",
+		"routes_total", sum.Routes,
+		"included", sum.Included,
+		"skipped_no_hostname", sum.Skipped,
+		"skipped_filtered", sum.Filtered,
+		"groups", sum.Groups,
+		"render_errors", sum.RenderErrors,
+		"configmap_changed", sum.Changed,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return sum, nil
 }
 
 // ---------------------------------------------------------------------------
 // Kubernetes helpers
 // ---------------------------------------------------------------------------
 
-// namespaceAnnotations is the annotation map for a single namespace.
-type namespaceAnnotations = map[string]string
+// namespaceMeta holds the annotations and labels of a single namespace.
+type namespaceMeta struct {
+	Annotations map[string]string
+	Labels      map[string]string
+}
+
+// fetchNamespaces returns the annotations and labels of every namespace. A
+// list younger than --namespace-cache-ttl is reused, so scans triggered in
+// quick succession do not re-list namespaces; the returned map is shared
+// between scans and must not be modified.
+func (c *Controller) fetchNamespaces(ctx context.Context) (map[string]namespaceMeta, error) {
+	ttl := time.Duration(c.cfg.NamespaceCacheTTL) * time.Second
+	c.mu.Lock()
+	cached, cachedAt := c.nsCache, c.nsCacheTime
+	c.mu.Unlock()
+	if cached != nil && time.Since(cachedAt) < ttl {
+		ctrlLog().Debug("using cached namespaces", "age", time.Since(cachedAt).Round(time.Second))
+		return cached, nil
+	}
 
-func (c *Controller) fetchNamespaces(ctx context.Context) (map[string]namespaceAnnotations, error) {
-	nsMap := make(map[string]namespaceAnnotations)
-	list, err := c.clients.Core.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	listedAt := time.Now()
+	nsMap := make(map[string]namespaceMeta)
+	list, err := listWithRetry(ctx, c, "namespaces", func(ctx context.Context) (*corev1.NamespaceList, error) {
+		return c.clients.Core.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("list namespaces: %w", err)
 	}
@@ -137,31 +537,105 @@ func (c *Controller) fetchNamespaces(ctx context.Context) (map[string]namespaceA
 		if ann == nil {
 			ann = make(map[string]string)
 		}
-		nsMap[ns.Name] = ann
+		labels := ns.Labels
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		nsMap[ns.Name] = namespaceMeta{Annotations: ann, Labels: labels}
 	}
+
+	c.mu.Lock()
+	c.nsCache, c.nsCacheTime = nsMap, listedAt
+	c.mu.Unlock()
 	return nsMap, nil
 }
 
+// fetchMaintenanceMessage reads the configured maintenance ConfigMap key and
+// returns it as a Homer message. A missing ConfigMap or empty key yields nil so
+// the banner disappears once the incident is cleared.
+func (c *Controller) fetchMaintenanceMessage(ctx context.Context) (*MessageData, error) {
+	src := c.cfg.Maintenance
+	if src.Name == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+	cm, err := c.clients.Core.CoreV1().ConfigMaps(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get configmap %s/%s: %w", src.Namespace, src.Name, err)
+	}
+
+	content := strings.TrimSpace(cm.Data[src.Key])
+	if content == "" {
+		return nil, nil
+	}
+	ctrlLog().Debug("maintenance banner active", "namespace", src.Namespace, "name", src.Name)
+	return &MessageData{Style: src.Style, Title: src.Title, Icon: src.Icon, Content: content}, nil
+}
+
+// loadTemplate returns the template source for this scan. A local
+// --template-path wins over --template-dir, then --template-configmap, then
+// the built-in template for --homer-schema. The ConfigMap is re-read every
+// scan so edits take effect without a restart.
+func (c *Controller) loadTemplate(ctx context.Context) (templateSource, error) {
+	if path := c.cfg.TemplatePath; path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return templateSource{}, fmt.Errorf("read custom template %q: %w", path, err)
+		}
+		return singleTemplate(string(raw)), nil
+	}
+	if dir := c.cfg.TemplateDir; dir != "" {
+		return loadTemplateDir(dir, c.cfg.TemplateEntry)
+	}
+
+	src := c.cfg.TemplateConfigMap
+	if src.Name == "" {
+		return singleTemplate(builtinTemplate(c.cfg.HomerSchema)), nil
+	}
+
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+	cm, err := c.clients.Core.CoreV1().ConfigMaps(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+	if err != nil {
+		return templateSource{}, fmt.Errorf("get configmap %s/%s: %w", src.Namespace, src.Name, err)
+	}
+	raw, ok := cm.Data[src.Key]
+	if !ok {
+		return templateSource{}, fmt.Errorf("configmap %s/%s has no key %q", src.Namespace, src.Name, src.Key)
+	}
+	ctrlLog().Debug("using template from configmap", "namespace", src.Namespace, "name", src.Name, "key", src.Key)
+	return singleTemplate(raw), nil
+}
+
+// staticMessage returns the message configured with the --message-* flags, or
+// nil when no content is set. An active maintenance banner takes precedence.
+func (c *Controller) staticMessage() *MessageData {
+	m := c.cfg.Message
+	if strings.TrimSpace(m.Content) == "" {
+		return nil
+	}
+	return &MessageData{Style: m.Style, Title: m.Title, Icon: m.Icon, Content: m.Content}
+}
+
 func (c *Controller) fetchHTTPRoutes(ctx context.Context) ([]map[string]interface{}, error) {
-	list, err := c.clients.Gateway.GatewayV1().HTTPRoutes("").List(ctx, metav1.ListOptions{})
+	list, err := listWithRetry(ctx, c, "httproutes", func(ctx context.Context) (*gwv1.HTTPRouteList, error) {
+		return c.clients.Gateway.GatewayV1().HTTPRoutes("").List(ctx, c.routeListOptions())
+	})
 	if err != nil {
-		return nil, fmt.Errorf("list httproutes: %w", err)
+		return nil, fmt.Errorf("list httproutes: %w", c.missingAPI(err, "HTTPRoute"))
 	}
 
 	routes := make([]map[string]interface{}, 0, len(list.Items))
 	for _, r := range list.Items {
 		// Build a minimal map that mirrors the Python dict structure so we
 		// can share the same annotation-processing logic.
-		parentRefs := make([]map[string]interface{}, 0, len(r.Spec.ParentRefs))
-		for _, pr := range r.Spec.ParentRefs {
-			parentRefs = append(parentRefs, map[string]interface{}{
-				"name": string(pr.Name),
-			})
-		}
-		hostnames := make([]string, 0, len(r.Spec.Hostnames))
-		for _, h := range r.Spec.Hostnames {
-			hostnames = append(hostnames, string(h))
-		}
+		parentRefs := parentRefMaps(r.Spec.ParentRefs, r.Namespace)
+		hostnames := hostnameStrings(r.Spec.Hostnames)
 
 		ann := r.Annotations
 		if ann == nil {
@@ -169,11 +643,16 @@ func (c *Controller) fetchHTTPRoutes(ctx context.Context) ([]map[string]interfac
 		}
 
 		routes = append(routes, map[string]interface{}{
-			"namespace":   r.Namespace,
-			"name":        r.Name,
-			"annotations": ann,
-			"parentRefs":  parentRefs,
-			"hostnames":   hostnames,
+			"kind":              "HTTPRoute",
+			"namespace":         r.Namespace,
+			"name":              r.Name,
+			"annotations":       ann,
+			"labels":            r.Labels,
+			"parentRefs":        parentRefs,
+			"parentStatuses":    acceptedConditions(r.Status.RouteStatus),
+			"hostnames":         hostnames,
+			"backendRefs":       backendServiceRefs(httpBackendRefs(r.Spec.Rules), r.Namespace),
+			"creationTimestamp": r.CreationTimestamp.Time,
 		})
 	}
 	return routes, nil
@@ -183,45 +662,190 @@ func (c *Controller) fetchHTTPRoutes(ctx context.Context) ([]map[string]interfac
 // Filtering
 // ---------------------------------------------------------------------------
 
-func (c *Controller) shouldInclude(route map[string]interface{}) bool {
+// shouldInclude decides whether a route becomes a dashboard item. A route
+// without its own enabled annotation inherits the one on its namespace, so a
+// namespace can opt all of its routes in (or out) at once.
+func (c *Controller) shouldInclude(route map[string]interface{}, nsMap map[string]namespaceMeta) bool {
 	ann := routeAnnotations(route)
-	enabled := strings.ToLower(ann[config.AnnotationPrefix+"/enabled"])
 	ns := route["namespace"].(string)
 	name := route["name"].(string)
+	enabled := strings.ToLower(ann[config.AnnotationPrefix+"/enabled"])
+	if enabled == "" {
+		enabled = strings.ToLower(nsMap[ns].Annotations[config.AnnotationPrefix+"/enabled"])
+	}
 
-	if c.cfg.HasFilters() {
-		// Opt-out mode: include unless explicitly disabled.
+	if c.isSelf(ns, name) {
+		ctrlLog().Debug("excluding route: belongs to homer-sync itself", "namespace", ns, "name", name)
+		return false
+	}
+
+	if !c.namespaceAllowed(ns) {
+		ctrlLog().Debug("excluding route: namespace filtered", "namespace", ns, "name", name)
+		return false
+	}
+
+	if c.cfg.RequireAccepted {
+		if ok, reason := routeAccepted(route); !ok {
+			ctrlLog().Debug("excluding route: not accepted by any parent", "namespace", ns, "name", name, "reason", reason)
+			return false
+		}
+	}
+
+	switch c.cfg.FilterMode {
+	case config.FilterModeOptOut:
+		// Include unless explicitly disabled.
 		if enabled == "false" {
-			slog.Debug("excluding route: disabled by annotation", "namespace", ns, "name", name)
+			ctrlLog().Debug("excluding route: disabled by annotation", "namespace", ns, "name", name)
+			return false
+		}
+		return c.matchesFilters(route, ns, name)
+	case config.FilterModeStrict:
+		// Require both the annotation and the filters.
+		if enabled != "true" {
+			ctrlLog().Debug("excluding route: not enabled by annotation", "namespace", ns, "name", name)
 			return false
 		}
+		return c.matchesFilters(route, ns, name)
+	default:
+		// Opt-in: only include if explicitly enabled.
+		return enabled == "true"
+	}
+}
 
-		if len(c.cfg.GatewayNames) > 0 {
-			if !matchesGateway(route, c.cfg.GatewayNames) {
-				slog.Debug("excluding route: no matching gateway", "namespace", ns, "name", name, "gateways", c.cfg.GatewayNames)
-				return false
-			}
+// matchesFilters applies the configured gateway and domain filters; an unset
+// filter matches every route.
+func (c *Controller) matchesFilters(route map[string]interface{}, ns, name string) bool {
+	if len(c.cfg.GatewayNames) > 0 || len(c.cfg.GatewaySections) > 0 {
+		if !matchesGateway(route, c.cfg.GatewayNames, c.cfg.GatewaySections) {
+			ctrlLog().Debug("excluding route: no matching gateway", "namespace", ns, "name", name,
+				"gateways", c.cfg.GatewayNames, "sections", c.cfg.GatewaySections)
+			return false
 		}
+	}
 
-		if len(c.cfg.DomainSuffixes) > 0 {
-			if !matchesDomainSuffix(route, c.cfg.DomainSuffixes) {
-				slog.Debug("excluding route: no hostname matches suffixes", "namespace", ns, "name", name, "suffixes", c.cfg.DomainSuffixes)
-				return false
+	if len(c.cfg.DomainSuffixes) > 0 {
+		if !matchesDomainSuffix(route, c.cfg.DomainSuffixes) {
+			ctrlLog().Debug("excluding route: no hostname matches suffixes", "namespace", ns, "name", name, "suffixes", c.cfg.DomainSuffixes)
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSelf reports whether the route is the controller's own, i.e. it lives in
+// the controller's namespace and shares its workload name.
+func (c *Controller) isSelf(ns, name string) bool {
+	if !c.cfg.SelfExclude || c.cfg.SelfNamespace == "" || c.cfg.SelfName == "" {
+		return false
+	}
+	return ns == c.cfg.SelfNamespace && name == c.cfg.SelfName
+}
+
+// namespaceAllowed applies the namespace include/exclude lists. When an
+// include list is set it acts as an allowlist and the exclude list is ignored.
+func (c *Controller) namespaceAllowed(ns string) bool {
+	if len(c.cfg.NamespaceInclude) > 0 {
+		return matchesAnyName(ns, c.cfg.NamespaceInclude)
+	}
+	return !matchesAnyName(ns, c.cfg.NamespaceExclude)
+}
+
+// matchesAnyName reports whether name equals one of patterns. Patterns
+// containing glob metacharacters (*, ?, [) are matched with path.Match.
+func matchesAnyName(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?[") {
+			if ok, err := path.Match(p, name); err == nil && ok {
+				return true
 			}
+			continue
 		}
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
 
-		return true
+// dropExcludedHostnames returns route without the hostnames matching
+// --exclude-hostnames (exact names or globs), so the link is chosen among the
+// remaining hostnames. It reports false when every hostname was excluded;
+// routes that never had hostnames are returned unchanged.
+func (c *Controller) dropExcludedHostnames(route map[string]interface{}) (map[string]interface{}, bool) {
+	hostnames, _ := route["hostnames"].([]string)
+	if len(c.cfg.ExcludeHostnames) == 0 || len(hostnames) == 0 {
+		return route, true
 	}
 
-	// Opt-in mode: only include if explicitly enabled.
-	return enabled == "true"
+	kept := make([]string, 0, len(hostnames))
+	for _, h := range hostnames {
+		if matchesAnyName(strings.TrimSuffix(h, "."), c.cfg.ExcludeHostnames) {
+			continue
+		}
+		kept = append(kept, h)
+	}
+	if len(kept) == len(hostnames) {
+		return route, true
+	}
+	if len(kept) == 0 {
+		ctrlLog().Debug("excluding route: all hostnames excluded", "namespace", route["namespace"], "name", route["name"], "hostnames", hostnames)
+		return route, false
+	}
+
+	out := make(map[string]interface{}, len(route))
+	for k, v := range route {
+		out[k] = v
+	}
+	out["hostnames"] = kept
+	return out, true
+}
+
+// routeAccepted reports whether at least one parent accepted the route. Kinds
+// without Gateway API status (Ingress) always pass. When no parent accepted it,
+// the reason of the first non-accepting parent is returned for logging.
+func routeAccepted(route map[string]interface{}) (bool, string) {
+	statuses, ok := route["parentStatuses"].([]map[string]interface{})
+	if !ok {
+		return true, ""
+	}
+	reason := "NoParentStatus"
+	for i, st := range statuses {
+		if st["status"] == string(metav1.ConditionTrue) {
+			return true, ""
+		}
+		if i == 0 {
+			reason = fmt.Sprintf("%s: Accepted=%v (%v)", st["parent"], st["status"], st["reason"])
+		}
+	}
+	return false, reason
 }
 
-func matchesGateway(route map[string]interface{}, names []string) bool {
+// matchesGateway reports whether any parentRef matches one of names and, when
+// sections is set, attaches to one of those listener sections. A
+// "namespace/name" entry must match both; a plain name matches a gateway of
+// that name in any namespace. An empty names list matches any gateway.
+func matchesGateway(route map[string]interface{}, names, sections []string) bool {
 	refs, _ := route["parentRefs"].([]map[string]interface{})
 	for _, ref := range refs {
+		if len(sections) > 0 {
+			section, _ := ref["sectionName"].(string)
+			if !slices.Contains(sections, section) {
+				continue
+			}
+		}
+		if len(names) == 0 {
+			return true
+		}
 		n, _ := ref["name"].(string)
+		ns, _ := ref["namespace"].(string)
 		for _, want := range names {
+			if wantNS, wantName, ok := strings.Cut(want, "/"); ok {
+				if ns == wantNS && n == wantName {
+					return true
+				}
+				continue
+			}
 			if n == want {
 				return true
 			}
@@ -230,11 +854,16 @@ func matchesGateway(route map[string]interface{}, names []string) bool {
 	return false
 }
 
+// matchesDomainSuffix reports whether any hostname matches one of suffixes.
+// Plain entries are literal suffixes; entries containing glob metacharacters
+// must match the whole hostname via path.Match, where * also spans dots, so
+// "*.example.com" matches any subdomain at any depth but not example.com
+// itself. Trailing dots of fully-qualified names are ignored on both sides.
 func matchesDomainSuffix(route map[string]interface{}, suffixes []string) bool {
 	hostnames, _ := route["hostnames"].([]string)
 	for _, h := range hostnames {
 		for _, s := range suffixes {
-			if strings.HasSuffix(h, s) {
+			if hostMatchesSuffix(h, s) {
 				return true
 			}
 		}
@@ -242,66 +871,372 @@ func matchesDomainSuffix(route map[string]interface{}, suffixes []string) bool {
 	return false
 }
 
+// hostMatchesSuffix reports whether hostname h matches suffix s, using the
+// literal-suffix or glob semantics of matchesDomainSuffix.
+func hostMatchesSuffix(h, s string) bool {
+	h = strings.TrimSuffix(h, ".")
+	s = strings.TrimSuffix(s, ".")
+	if strings.ContainsAny(s, "*?[") {
+		ok, err := path.Match(s, h)
+		return err == nil && ok
+	}
+	return strings.HasSuffix(h, s)
+}
+
+// preferredHostname picks the hostname a single-tile item links to. With
+// --prefer-hostname-suffix, the candidates are the hostnames matching the
+// first listed suffix that matches any; otherwise all hostnames. With
+// --prefer-shortest the shortest candidate wins, else the first; ties keep
+// spec order, so the choice is deterministic.
+func (c *Controller) preferredHostname(hostnames []string) string {
+	if len(hostnames) == 0 {
+		return ""
+	}
+	candidates := hostnames
+	for _, s := range c.cfg.PreferSuffixes {
+		var matched []string
+		for _, h := range hostnames {
+			if hostMatchesSuffix(h, s) {
+				matched = append(matched, h)
+			}
+		}
+		if len(matched) > 0 {
+			candidates = matched
+			break
+		}
+	}
+	best := candidates[0]
+	if c.cfg.PreferShortest {
+		for _, h := range candidates[1:] {
+			if len(h) < len(best) {
+				best = h
+			}
+		}
+	}
+	return best
+}
+
+// itemTarget returns the home.mirceanton.com/target annotation, falling back
+// to --default-target. Values Homer does not document are warned about but
+// kept, since browsers also accept named windows.
+func (c *Controller) itemTarget(ns, name string, ann map[string]string) string {
+	target := strings.TrimSpace(ann[config.AnnotationPrefix+"/target"])
+	if target == "" {
+		return c.cfg.DefaultTarget
+	}
+	if !slices.Contains(config.LinkTargets, target) {
+		ctrlLog().Warn("unknown target annotation", "namespace", ns, "name", name, "value", target)
+	}
+	return target
+}
+
+// domainGroup returns the group of the first --domain-group-map rule whose
+// suffix matches hostname, or "" when none does.
+func (c *Controller) domainGroup(hostname string) string {
+	for _, r := range c.cfg.DomainGroups {
+		if hostMatchesSuffix(hostname, r.Suffix) {
+			return r.Group
+		}
+	}
+	return ""
+}
+
 // ---------------------------------------------------------------------------
 // Item extraction
 // ---------------------------------------------------------------------------
 
 func (c *Controller) extractItem(
 	route map[string]interface{},
-	nsMap map[string]namespaceAnnotations,
-	groupIconCache map[string]string,
+	nsMap map[string]namespaceMeta,
+	nsNames []string,
 ) (ServiceItem, bool) {
 	ann := routeAnnotations(route)
 	ns := route["namespace"].(string)
 	name := route["name"].(string)
 
+	created, _ := route["creationTimestamp"].(time.Time)
+
 	hostnames, _ := route["hostnames"].([]string)
-	if len(hostnames) == 0 {
-		slog.Warn("skipping route: no hostnames defined", "namespace", ns, "name", name)
+	hostname := c.preferredHostname(hostnames)
+
+	itemURL := c.routeURL(route, hostname)
+	if itemURL == "" {
+		ctrlLog().Warn("skipping route: no hostnames defined", "namespace", ns, "name", name)
 		return ServiceItem{}, false
 	}
-	url := "https://" + hostnames[0]
 
-	nsAnn := nsMap[ns]
+	nsAnn := nsMap[ns].Annotations
 
-	var group string
+	// The icon is this route's candidate; collectItems settles on the first
+	// route's candidate for each group.
+	var group, groupIcon string
 	if override, ok := ann[config.AnnotationPrefix+"/group"]; ok && override != "" {
 		group = override
-		if _, seen := groupIconCache[group]; !seen {
-			groupIconCache[group] = resolveGroupIconForName(group, nsMap)
-		}
+		groupIcon = c.resolveGroupIconForName(group, nsMap, nsNames)
+	} else if dg := c.domainGroup(hostname); dg != "" && nsAnn[config.AnnotationPrefix+"/group"] == "" {
+		group = dg
+		groupIcon = c.resolveGroupIconForName(group, nsMap, nsNames)
 	} else {
-		group = namespaceGroupName(ns, nsAnn)
-		if _, seen := groupIconCache[group]; !seen {
-			groupIconCache[group] = namespaceGroupIcon(nsAnn)
+		group = c.namespaceGroupName(ns, nsMap[ns])
+		groupIcon = c.namespaceGroupIcon(nsAnn)
+	}
+
+	sortVal := parseItemSort(ann[config.AnnotationPrefix+"/sort"])
+
+	column := 0
+	if cv, ok := ann[config.AnnotationPrefix+"/column"]; ok && cv != "" {
+		n, err := strconv.Atoi(cv)
+		if err != nil || n <= 0 {
+			ctrlLog().Warn("ignoring invalid column annotation", "namespace", ns, "name", name, "value", cv)
+		} else {
+			column = n
 		}
 	}
 
-	sortVal := 0
-	if sv, ok := ann[config.AnnotationPrefix+"/sort"]; ok && sv != "" {
-		fmt.Sscanf(sv, "%d", &sortVal)
+	tag := ann[config.AnnotationPrefix+"/tag"]
+	tagStyle := ann[config.AnnotationPrefix+"/tagstyle"]
+	if tag == "" {
+		tag, tagStyle = labelTag(nsMap[ns].Labels, c.cfg.LabelTags)
+	}
+	_, env := c.stripNamespace(ns)
+	if tag == "" {
+		tag = env
+	}
+
+	apiKeyRef, _ := c.apiKeyRef(ns, name, ann)
+
+	probeURL := stringOr(strings.TrimSpace(ann[config.AnnotationPrefix+"/probe-url"]), itemURL)
+	checkType, endpoint := healthCheck(ann, probeURL)
+	var headers HealthHeaders
+	if checkType != "" {
+		headers = healthCheckHeaders(ns, name, ann)
+	}
+
+	itemName := stringOr(ann[config.AnnotationPrefix+"/name"], name)
+	icon := ann[config.AnnotationPrefix+"/icon"]
+	logo := ""
+	if icon == "" && c.cfg.AutoIcon {
+		logo = autoIconURL(c.cfg.IconBaseURL, itemName)
 	}
 
 	return ServiceItem{
-		Name:      stringOr(ann[config.AnnotationPrefix+"/name"], name),
-		Subtitle:  ann[config.AnnotationPrefix+"/subtitle"],
-		URL:       url,
-		Icon:      ann[config.AnnotationPrefix+"/icon"],
-		Group:     group,
-		GroupIcon: groupIconCache[group],
-		Sort:      sortVal,
+		Namespace:    ns,
+		Route:        name,
+		Name:         itemName,
+		Subtitle:     c.itemSubtitle(route, ann),
+		URL:          itemURL,
+		Icon:         icon,
+		Logo:         logo,
+		Group:        group,
+		GroupIcon:    groupIcon,
+		Sort:         sortVal,
+		SortKey:      ann[config.AnnotationPrefix+"/sort-key"],
+		Column:       column,
+		Tag:          tag,
+		TagStyle:     tagStyle,
+		Keywords:     splitAnnotationList(ann[config.AnnotationPrefix+"/keywords"]),
+		Class:        strings.TrimSpace(ann[config.AnnotationPrefix+"/class"]),
+		Background:   strings.TrimSpace(ann[config.AnnotationPrefix+"/background"]),
+		Dashboards:   c.parseDashboards(ns, name, ann[config.AnnotationPrefix+"/dashboards"]),
+		Created:      created,
+		APIKeyRef:    apiKeyRef,
+		Type:         checkType,
+		Endpoint:     endpoint,
+		Environment:  env,
+		Target:       c.itemTarget(ns, name, ann),
+		Headers:      headers,
+		ProbeURL:     probeURL,
+		Hidden:       strings.EqualFold(ann[config.AnnotationPrefix+"/hidden"], "true"),
+		Unsearchable: strings.EqualFold(ann[config.AnnotationPrefix+"/unsearchable"], "true"),
 	}, true
 }
 
+// itemSubtitle returns the subtitle annotation or, when it is unset, the
+// first non-empty --subtitle-fallback key, looked up as a route annotation
+// (including merged backend Service annotations) and then as a route label.
+func (c *Controller) itemSubtitle(route map[string]interface{}, ann map[string]string) string {
+	if s := ann[config.AnnotationPrefix+"/subtitle"]; s != "" {
+		return s
+	}
+	labels := routeLabels(route)
+	for _, key := range c.cfg.SubtitleFallback {
+		if s := strings.TrimSpace(stringOr(ann[key], labels[key])); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Sort values the home.mirceanton.com/sort keywords "first" and "last" map
+// to, ordering the item before or after every integer sort value.
+const (
+	sortFirst = math.MinInt
+	sortLast  = math.MaxInt
+)
+
+// parseItemSort parses the sort annotation: an integer, or "first" or "last"
+// to pin the item to either end of its group. Anything else sorts as 0.
+func parseItemSort(raw string) int {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+		return 0
+	case "first":
+		return sortFirst
+	case "last":
+		return sortLast
+	}
+	n := 0
+	fmt.Sscanf(raw, "%d", &n)
+	return n
+}
+
+// splitAnnotationList splits a comma-separated annotation value into trimmed,
+// non-empty entries.
+func splitAnnotationList(raw string) []string {
+	var out []string
+	for _, k := range strings.Split(raw, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// parseDashboards splits the dashboards annotation into output names, warning
+// about names that match neither the default ConfigMap nor a --configmap-map
+// target.
+func (c *Controller) parseDashboards(ns, name, raw string) []string {
+	dashboards := splitAnnotationList(raw)
+	for _, d := range dashboards {
+		known := d == c.cfg.ConfigMapName
+		for _, m := range c.cfg.OutputMap {
+			known = known || d == m.Name
+		}
+		if !known {
+			ctrlLog().Warn("dashboards annotation names an unknown output", "namespace", ns, "name", name, "dashboard", d)
+		}
+	}
+	return dashboards
+}
+
+// autoIconURL maps a service name to a logo in an icon pack following the
+// dashboard-icons naming convention: lowercased, with runs of whitespace
+// replaced by a single hyphen, e.g. "Home Assistant" → <base>/home-assistant.png.
+func autoIconURL(base, name string) string {
+	slug := strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if slug == "" {
+		return ""
+	}
+	return strings.TrimSuffix(base, "/") + "/" + slug + ".png"
+}
+
+// healthCheck resolves the Homer status check for an item. The
+// home.mirceanton.com/healthcheck annotation enables it: "true" selects the
+// Ping type, any other value is used as the Homer type verbatim. The endpoint
+// defaults to the item's probe URL unless healthcheck-endpoint overrides it.
+func healthCheck(ann map[string]string, probeURL string) (checkType, endpoint string) {
+	v := strings.TrimSpace(ann[config.AnnotationPrefix+"/healthcheck"])
+	switch strings.ToLower(v) {
+	case "", "false":
+		return "", ""
+	case "true":
+		checkType = "Ping"
+	default:
+		checkType = v
+	}
+	return checkType, stringOr(ann[config.AnnotationPrefix+"/healthcheck-endpoint"], probeURL)
+}
+
+// healthCheckHeaders parses the home.mirceanton.com/healthcheck-headers
+// annotation, a JSON object of header names to values. Invalid JSON is
+// ignored with a warning that leaves out the value, which may hold secrets.
+func healthCheckHeaders(ns, name string, ann map[string]string) HealthHeaders {
+	raw := strings.TrimSpace(ann[config.AnnotationPrefix+"/healthcheck-headers"])
+	if raw == "" {
+		return nil
+	}
+	var headers HealthHeaders
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		ctrlLog().Warn("ignoring invalid healthcheck-headers annotation", "namespace", ns, "name", name)
+		return nil
+	}
+	return headers
+}
+
+// labelTag returns the tag and tag style of the first rule whose label value
+// matches the namespace labels. The matched label value becomes the tag text.
+func labelTag(labels map[string]string, rules []config.LabelTag) (string, string) {
+	for _, r := range rules {
+		if labels[r.Label] == r.Value {
+			return r.Value, r.TagStyle
+		}
+	}
+	return "", ""
+}
+
 // ---------------------------------------------------------------------------
 // Namespace helpers
 // ---------------------------------------------------------------------------
 
-func namespaceGroupName(ns string, ann map[string]string) string {
-	if override, ok := ann[config.AnnotationPrefix+"/group"]; ok && override != "" {
+// namespaceGroupName returns the group a namespace's routes land in. An explicit
+// group annotation wins; otherwise the --group-by annotation or label value is
+// used, falling back to the namespace name when it is unset. Derived names are
+// title-cased with dashes turned into spaces.
+func (c *Controller) namespaceGroupName(ns string, meta namespaceMeta) string {
+	if override, ok := meta.Annotations[config.AnnotationPrefix+"/group"]; ok && override != "" {
 		return override
 	}
-	return titleCase(strings.ReplaceAll(ns, "-", " "))
+	name, _ := c.stripNamespace(ns)
+	base := name
+	switch by := c.cfg.GroupBy; by.Source {
+	case "annotation":
+		base = stringOr(meta.Annotations[by.Key], name)
+	case "label":
+		base = stringOr(meta.Labels[by.Key], name)
+	}
+	return titleCase(strings.ReplaceAll(base, "-", " "))
+}
+
+// stripNamespace removes the first matching --strip-namespace-prefix and
+// --strip-namespace-suffix from ns, so prod-media and staging-media share a
+// "Media" group. It returns the remaining name and the stripped parts with
+// surrounding dashes trimmed, joined by "-" when both matched. A strip that
+// would leave nothing is not applied.
+func (c *Controller) stripNamespace(ns string) (string, string) {
+	name := ns
+	var env []string
+	for _, p := range c.cfg.StripNSPrefixes {
+		if p != "" && len(name) > len(p) && strings.HasPrefix(name, p) {
+			env = append(env, strings.Trim(p, "-"))
+			name = name[len(p):]
+			break
+		}
+	}
+	for _, sfx := range c.cfg.StripNSSuffixes {
+		if sfx != "" && len(name) > len(sfx) && strings.HasSuffix(name, sfx) {
+			env = append(env, strings.Trim(sfx, "-"))
+			name = name[:len(name)-len(sfx)]
+			break
+		}
+	}
+	return name, strings.Join(env, "-")
+}
+
+// disabledGroups returns the groups whose routes are all skipped: those named
+// by --disable-groups and those of namespaces annotated
+// home.mirceanton.com/group-enabled: "false". A disabled parent group also
+// disables its sub-groups.
+func (c *Controller) disabledGroups(nsMap map[string]namespaceMeta) map[string]bool {
+	disabled := make(map[string]bool)
+	for _, g := range c.cfg.DisableGroups {
+		disabled[g] = true
+	}
+	for ns, meta := range nsMap {
+		if strings.EqualFold(strings.TrimSpace(meta.Annotations[config.AnnotationPrefix+"/group-enabled"]), "false") {
+			disabled[c.namespaceGroupName(ns, meta)] = true
+		}
+	}
+	return disabled
 }
 
 // titleCase capitalises the first letter of each space-separated word.
@@ -316,111 +1251,588 @@ func titleCase(s string) string {
 	return strings.Join(words, " ")
 }
 
-func namespaceGroupIcon(ann map[string]string) string {
+// namespaceGroupIcon returns the namespace's group-icon annotation, falling
+// back to --default-group-icon.
+func (c *Controller) namespaceGroupIcon(ann map[string]string) string {
 	if icon, ok := ann[config.AnnotationPrefix+"/group-icon"]; ok && icon != "" {
 		return icon
 	}
-	return "fas fa-globe"
+	return c.cfg.DefaultGroupIcon
+}
+
+// collectLinks gathers Homer links declared by the home.mirceanton.com/link
+// namespace annotation, which holds a JSON object or array of objects with
+// name, url, icon and optional target. Duplicates (same name and URL) keep
+// the entry from the alphabetically first namespace, and the result is sorted
+// by name, then URL. nsNames holds the keys of nsMap, sorted.
+func collectLinks(nsMap map[string]namespaceMeta, nsNames []string) []LinkData {
+	seen := make(map[LinkData]bool)
+	var links []LinkData
+	for _, ns := range nsNames {
+		raw := strings.TrimSpace(nsMap[ns].Annotations[config.AnnotationPrefix+"/link"])
+		if raw == "" {
+			continue
+		}
+		var parsed []LinkData
+		if strings.HasPrefix(raw, "[") {
+			if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+				ctrlLog().Warn("ignoring invalid link annotation", "namespace", ns, "error", err)
+				continue
+			}
+		} else {
+			var l LinkData
+			if err := json.Unmarshal([]byte(raw), &l); err != nil {
+				ctrlLog().Warn("ignoring invalid link annotation", "namespace", ns, "error", err)
+				continue
+			}
+			parsed = []LinkData{l}
+		}
+		for _, l := range parsed {
+			if l.Name == "" || l.URL == "" {
+				ctrlLog().Warn("ignoring link without name or url", "namespace", ns)
+				continue
+			}
+			key := LinkData{Name: l.Name, URL: l.URL}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			links = append(links, l)
+		}
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		if links[i].Name != links[j].Name {
+			return links[i].Name < links[j].Name
+		}
+		return links[i].URL < links[j].URL
+	})
+	return links
 }
 
-// resolveGroupIconForName walks all namespaces to find the first whose group
-// name matches the provided group, then returns its icon.
-func resolveGroupIconForName(group string, nsMap map[string]namespaceAnnotations) string {
-	for ns, ann := range nsMap {
-		if namespaceGroupName(ns, ann) == group {
-			return namespaceGroupIcon(ann)
+// resolveGroupIconForName returns the icon of the first namespace (by name)
+// whose group name matches the provided group, so namespaces that disagree
+// always resolve to the same icon. nsNames holds the keys of nsMap, sorted.
+func (c *Controller) resolveGroupIconForName(group string, nsMap map[string]namespaceMeta, nsNames []string) string {
+	for _, ns := range nsNames {
+		if c.namespaceGroupName(ns, nsMap[ns]) == group {
+			return c.namespaceGroupIcon(nsMap[ns].Annotations)
 		}
 	}
-	return "fas fa-globe"
+	return c.cfg.DefaultGroupIcon
 }
 
 // ---------------------------------------------------------------------------
 // Template rendering
 // ---------------------------------------------------------------------------
 
-func (c *Controller) buildTemplateData(groups map[string][]ServiceItem) (string, error) {
-	// Sort groups alphabetically (mirrors Jinja2's dictsort).
-	groupNames := make([]string, 0, len(groups))
+// resolveGroupColumns returns the column count requested by the
+// home.mirceanton.com/columns annotation of the first namespace (by name) that
+// maps to group, falling back to the global --columns value.
+func (c *Controller) resolveGroupColumns(group string, nsMap map[string]namespaceMeta, nsNames []string) int {
+	for _, ns := range nsNames {
+		ann := nsMap[ns].Annotations
+		if c.namespaceGroupName(ns, nsMap[ns]) != group {
+			continue
+		}
+		raw, ok := ann[config.AnnotationPrefix+"/columns"]
+		if !ok || raw == "" {
+			continue
+		}
+		cols, err := strconv.Atoi(raw)
+		if err != nil || cols <= 0 {
+			ctrlLog().Warn("ignoring invalid columns annotation", "namespace", ns, "value", raw)
+			continue
+		}
+		return cols
+	}
+	return c.cfg.Columns
+}
+
+// resolveGroupSort returns the home.mirceanton.com/group-sort value for group.
+// Namespaces (by name) that map to the group are consulted first; for groups
+// formed by a route-level group override, the namespaces contributing items
+// are consulted next. Unset or invalid values yield 0.
+func (c *Controller) resolveGroupSort(group string, items []ServiceItem, nsMap map[string]namespaceMeta, nsNames []string) int {
+	contributing := make([]string, 0, len(items))
+	for _, it := range items {
+		contributing = append(contributing, it.Namespace)
+	}
+	sort.Strings(contributing)
+
+	candidates := make([]string, 0, len(nsNames)+len(contributing))
+	for _, ns := range nsNames {
+		if c.namespaceGroupName(ns, nsMap[ns]) == group {
+			candidates = append(candidates, ns)
+		}
+	}
+	candidates = append(candidates, slices.Compact(contributing)...)
+
+	for _, ns := range candidates {
+		raw, ok := nsMap[ns].Annotations[config.AnnotationPrefix+"/group-sort"]
+		if !ok || raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			ctrlLog().Warn("ignoring invalid group-sort annotation", "namespace", ns, "value", raw)
+			continue
+		}
+		return v
+	}
+	return 0
+}
+
+// buildTemplateData orders groups and items according to the configured
+// OrderPolicy and renders the result as the given --auto-shard shard (see
+// TemplateData.Shard). The summary group only goes on shard 0.
+func (c *Controller) buildTemplateData(
+	groups map[string][]ServiceItem,
+	nsMap map[string]namespaceMeta,
+	nsNames []string,
+	message *MessageData,
+	tmplSrc templateSource,
+	shard int,
+) (string, error) {
+	// Build in group key order so groups tying on every order key (e.g.
+	// "Media / TV" and "Media/TV") come out the same on every scan.
+	names := make([]string, 0, len(groups))
 	for g := range groups {
-		groupNames = append(groupNames, g)
+		names = append(names, g)
 	}
-	sort.Strings(groupNames)
+	sort.Strings(names)
 
-	groupData := make([]GroupData, 0, len(groupNames))
-	for _, gName := range groupNames {
+	groupData := make([]GroupData, 0, len(groups))
+	total := 0
+	for _, gName := range names {
 		items := groups[gName]
+		sortItems(items, c.cfg.Order.ItemKeys)
 		icon := ""
 		if len(items) > 0 {
 			icon = items[0].GroupIcon
 		}
+		parent, sub := splitGroupPath(gName)
 		gd := GroupData{
-			Name: gName,
-			Icon: icon,
+			Name:     parent,
+			SubGroup: sub,
+			Icon:     icon,
+			Columns:  c.resolveGroupColumns(gName, nsMap, nsNames),
+			Sort:     c.resolveGroupSort(gName, items, nsMap, nsNames),
 		}
 		for _, si := range items {
+			if si.Hidden {
+				ctrlLog().Debug("hiding service from dashboard", "group", gName, "name", si.Name)
+				continue
+			}
 			gd.Items = append(gd.Items, si)
 		}
+		gd.Items = c.resolveDuplicates(gName, gd.Items)
+		if len(gd.Items) == 0 {
+			continue
+		}
+		if gd.ColumnItems = layoutColumns(gd.Items, gd.Columns); gd.ColumnItems != nil {
+			gd.Items = rowMajor(gd.ColumnItems)
+		}
+		gd.Count = len(gd.Items)
+		total += gd.Count
 		groupData = append(groupData, gd)
 	}
+	sortGroups(groupData, c.cfg.Order)
+	totalGroups := len(groupData)
+
+	if summary, ok := c.summaryGroup(groupData); ok && shard == 0 {
+		summary.Count = len(summary.Items)
+		groupData = append([]GroupData{summary}, groupData...)
+	}
 
 	data := TemplateData{
 		Title:    c.cfg.Title,
 		Subtitle: c.cfg.Subtitle,
+		Theme:    c.cfg.Theme,
+		Colors:   c.cfg.Colors,
 		Columns:  c.cfg.Columns,
+		Message:  message,
+		Links:    collectLinks(nsMap, nsNames),
 		Groups:   groupData,
+
+		TotalServices: total,
+		TotalGroups:   totalGroups,
+		Shard:         shard,
 	}
-	return renderConfig(data, c.cfg.TemplatePath)
+	return renderConfig(data, tmplSrc, !c.cfg.NoHeader, c.cfg.CanonicalYAML)
+}
+
+// dedupeItems drops items whose URL was already taken by an earlier item,
+// e.g. the same app mirrored into several namespaces. Items are compared in
+// item sort order (namespace, then route name breaking ties) so the kept one
+// is stable across scans.
+func (c *Controller) dedupeItems(items []ServiceItem) []ServiceItem {
+	ordered := slices.Clone(items)
+	slices.SortStableFunc(ordered, func(a, b ServiceItem) int {
+		if r := compareItems(a, b, c.cfg.Order.ItemKeys); r != 0 {
+			return r
+		}
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Route, b.Route))
+	})
+
+	seen := make(map[string]ServiceItem, len(ordered))
+	out := ordered[:0]
+	for _, it := range ordered {
+		if first, dup := seen[it.URL]; dup {
+			ctrlLog().Info("dropping duplicate service", "url", it.URL,
+				"namespace", it.Namespace, "name", it.Route,
+				"kept_namespace", first.Namespace, "kept_name", first.Route)
+			continue
+		}
+		seen[it.URL] = it
+		out = append(out, it)
+	}
+	return out
+}
+
+// resolveDuplicates handles items sharing a display name within one group
+// according to --on-duplicate. The first item (in sort order) always keeps its
+// name; later ones are logged (warn), renamed with their namespace appended
+// (suffix), or dropped (skip).
+func (c *Controller) resolveDuplicates(group string, items []ServiceItem) []ServiceItem {
+	first := make(map[string]ServiceItem, len(items))
+	out := items[:0]
+	for _, it := range items {
+		prev, dup := first[it.Name]
+		if !dup {
+			first[it.Name] = it
+			out = append(out, it)
+			continue
+		}
+
+		ctrlLog().Warn("duplicate service name in group",
+			"group", group, "name", it.Name,
+			"first", prev.Namespace+"/"+prev.Route,
+			"duplicate", it.Namespace+"/"+it.Route,
+			"action", c.cfg.OnDuplicate,
+		)
+		switch c.cfg.OnDuplicate {
+		case "skip":
+			continue
+		case "suffix":
+			it.Name = fmt.Sprintf("%s (%s)", it.Name, it.Namespace)
+		}
+		out = append(out, it)
+	}
+	return out
+}
+
+// splitGroupPath splits a "Parent/Child" group name into its two levels.
+// Segments are trimmed and empty ones dropped; any levels below the second
+// stay in sub, e.g. "a/b/c" yields "a" and "b/c". Names without a separator
+// have no sub-group.
+func splitGroupPath(group string) (parent, sub string) {
+	var segs []string
+	for _, seg := range strings.Split(group, "/") {
+		if seg = strings.TrimSpace(seg); seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	if len(segs) == 0 {
+		return "", ""
+	}
+	return segs[0], strings.Join(segs[1:], "/")
+}
+
+// summaryGroup builds the pinned summary group from the already grouped
+// services. "all" lists every service by name; "recent" keeps the newest
+// services by creation time. Like any other group, it is omitted when empty.
+func (c *Controller) summaryGroup(groups []GroupData) (GroupData, bool) {
+	sg := c.cfg.SummaryGroup
+	if sg.Mode == "" {
+		return GroupData{}, false
+	}
+
+	var items []ServiceItem
+	for _, g := range groups {
+		items = append(items, g.Items...)
+	}
+	if len(items) == 0 {
+		return GroupData{}, false
+	}
+
+	if sg.Mode == "recent" {
+		sortItems(items, []string{"created", "name"})
+		if len(items) > sg.Limit {
+			items = items[:sg.Limit]
+		}
+	} else {
+		sortItems(items, []string{"name"})
+	}
+
+	return GroupData{Name: sg.Name, Icon: sg.Icon, Columns: c.cfg.Columns, Items: items}, true
 }
 
 // ---------------------------------------------------------------------------
 // ConfigMap sync
 // ---------------------------------------------------------------------------
 
-func (c *Controller) syncConfigMap(ctx context.Context, rendered string) error {
-	name := c.cfg.ConfigMapName
+// outputTarget is one ConfigMap to write and the groups rendered into it.
+type outputTarget struct {
+	name   string
+	groups map[string][]ServiceItem
+}
+
+// splitOutputs assigns groups to the ConfigMaps configured via
+// --configmap-map. A group listed for several targets goes to each of them;
+// groups not listed anywhere go to the default ConfigMap, which is always
+// written. Items with a dashboards annotation only go to the targets it
+// names. Without a map this is the single default target.
+func (c *Controller) splitOutputs(groups map[string][]ServiceItem) []outputTarget {
+	def := outputTarget{name: c.cfg.ConfigMapName, groups: make(map[string][]ServiceItem)}
+	targets := make([]outputTarget, 0, len(c.cfg.OutputMap))
+	assigned := make(map[string]bool)
+
+	for _, m := range c.cfg.OutputMap {
+		t := outputTarget{name: m.Name, groups: make(map[string][]ServiceItem)}
+		for _, g := range m.Groups {
+			if items, ok := groups[g]; ok {
+				t.add(g, items)
+				assigned[g] = true
+			}
+		}
+		targets = append(targets, t)
+	}
+
+	for g, items := range groups {
+		if !assigned[g] {
+			def.add(g, items)
+		}
+	}
+	return append([]outputTarget{def}, targets...)
+}
+
+// add appends the items of group that may appear on t, honouring each
+// item's dashboards annotation. A group left without items is omitted.
+func (t *outputTarget) add(group string, items []ServiceItem) {
+	var kept []ServiceItem
+	for _, it := range items {
+		if len(it.Dashboards) == 0 || slices.Contains(it.Dashboards, t.name) {
+			kept = append(kept, it)
+		}
+	}
+	if len(kept) > 0 {
+		t.groups[group] = kept
+	}
+}
+
+// syncConfigMap writes rendered to the output named name (a ConfigMap, Secret
+// or file, per configuration) and reports whether anything was written.
+func (c *Controller) syncConfigMap(ctx context.Context, name, rendered string) (bool, error) {
+	if c.cfg.OutputFile != "" {
+		return c.syncFile(name, rendered)
+	}
+	if c.cfg.OutputKind == "secret" {
+		return c.syncSecret(ctx, name, rendered)
+	}
+
 	ns := c.cfg.ConfigMapNamespace
+	key := c.cfg.ConfigMapKey
 	hash := contentHash(rendered)
 
-	existing, err := c.clients.Core.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
+	getCtx, cancelGet := c.apiContext(ctx)
+	existing, err := c.output().CoreV1().ConfigMaps(ns).Get(getCtx, name, metav1.GetOptions{})
+	cancelGet()
 	if err != nil && !errors.IsNotFound(err) {
-		return fmt.Errorf("get configmap %s/%s: %w", ns, name, err)
+		return false, fmt.Errorf("get configmap %s/%s: %w", ns, name, err)
+	}
+
+	if c.cfg.DryRun {
+		exists := err == nil
+		current := ""
+		if exists {
+			current, _ = storedConfig(existing, key)
+		}
+		return false, printDryRun(os.Stdout, "configmap", ns, name, exists, current, rendered)
+	}
+
+	gz, encErr := c.encodeConfig(ns, name, rendered)
+	if encErr != nil {
+		return false, encErr
+	}
+
+	if c.cfg.ApplyMode == "ssa" {
+		if errors.IsNotFound(err) {
+			existing = nil
+		}
+		return c.applyConfigMap(ctx, name, rendered, gz, existing)
 	}
 
 	if errors.IsNotFound(err) {
 		cm := &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: ns,
+				Name:        name,
+				Namespace:   ns,
+				Labels:      c.cfg.OutputLabels,
+				Annotations: c.outputAnnotations(hash),
 			},
-			Data: map[string]string{"config.yml": rendered},
 		}
-		if _, err := c.clients.Core.CoreV1().ConfigMaps(ns).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
-			return fmt.Errorf("create configmap %s/%s: %w", ns, name, err)
+		if gz != nil {
+			cm.BinaryData = map[string][]byte{gzipKey(key): gz}
+		} else {
+			cm.Data = map[string]string{key: rendered}
 		}
-		slog.Info("created configmap", "namespace", ns, "name", name)
-		return nil
+		// Owner references are only set on create so an existing reference
+		// (or its deliberate removal) is never clobbered on update.
+		owner, err := c.ownerReference(ctx)
+		if err != nil {
+			return false, fmt.Errorf("resolve owner reference: %w", err)
+		}
+		if owner != nil {
+			cm.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		createCtx, cancelCreate := c.apiContext(ctx)
+		created, err := c.output().CoreV1().ConfigMaps(ns).Create(createCtx, cm, metav1.CreateOptions{})
+		cancelCreate()
+		if err != nil {
+			return false, fmt.Errorf("create configmap %s/%s: %w", ns, name, err)
+		}
+		syncLog().Info("created configmap", "namespace", ns, "name", name)
+		c.recordEvent(created, corev1.EventTypeNormal, reasonCreated, "Created Homer config")
+		return true, nil
+	}
+
+	// Skip update if content and storage form are unchanged.
+	live, compressed := storedConfig(existing, key)
+	if compressed == (gz != nil) && c.upToDate("configmap", ns, name, existing.ObjectMeta, live, rendered) {
+		syncLog().Debug("configmap already up to date", "namespace", ns, "name", name)
+		c.recordEvent(existing, corev1.EventTypeNormal, reasonUnchanged, "Homer config already up to date")
+		return false, nil
+	}
+
+	// Merge-patch only our key so other keys in the ConfigMap survive.
+	patch, err := dataPatch(configMapPatchFields(key, rendered, gz), c.cfg.OutputLabels, c.outputAnnotations(hash))
+	if err != nil {
+		return false, err
+	}
+	updateCtx, cancelUpdate := c.apiContext(ctx)
+	updated, err := c.output().CoreV1().ConfigMaps(ns).Patch(updateCtx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	cancelUpdate()
+	if err != nil {
+		return false, fmt.Errorf("patch configmap %s/%s: %w", ns, name, err)
+	}
+	syncLog().Info("updated configmap", "namespace", ns, "name", name)
+	c.recordEvent(updated, corev1.EventTypeNormal, reasonUpdated, "Updated Homer config")
+	return true, nil
+}
+
+// syncSecret mirrors syncConfigMap for --output-kind=secret, storing the
+// rendered config under the same data key.
+func (c *Controller) syncSecret(ctx context.Context, name, rendered string) (bool, error) {
+	ns := c.cfg.ConfigMapNamespace
+	key := c.cfg.ConfigMapKey
+	hash := contentHash(rendered)
+
+	getCtx, cancelGet := c.apiContext(ctx)
+	existing, err := c.output().CoreV1().Secrets(ns).Get(getCtx, name, metav1.GetOptions{})
+	cancelGet()
+	if err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("get secret %s/%s: %w", ns, name, err)
+	}
+
+	if c.cfg.DryRun {
+		exists := err == nil
+		current := ""
+		if exists {
+			current = string(existing.Data[key])
+		}
+		return false, printDryRun(os.Stdout, "secret", ns, name, exists, current, rendered)
+	}
+
+	if err := checkSize("secret", ns, name, len(rendered), false); err != nil {
+		return false, err
+	}
+
+	if c.cfg.ApplyMode == "ssa" {
+		if errors.IsNotFound(err) {
+			existing = nil
+		}
+		return c.applySecret(ctx, name, rendered, existing)
+	}
+
+	if errors.IsNotFound(err) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   ns,
+				Labels:      c.cfg.OutputLabels,
+				Annotations: c.outputAnnotations(hash),
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{key: []byte(rendered)},
+		}
+		owner, err := c.ownerReference(ctx)
+		if err != nil {
+			return false, fmt.Errorf("resolve owner reference: %w", err)
+		}
+		if owner != nil {
+			secret.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		createCtx, cancelCreate := c.apiContext(ctx)
+		created, err := c.output().CoreV1().Secrets(ns).Create(createCtx, secret, metav1.CreateOptions{})
+		cancelCreate()
+		if err != nil {
+			return false, fmt.Errorf("create secret %s/%s: %w", ns, name, err)
+		}
+		syncLog().Info("created secret", "namespace", ns, "name", name)
+		c.recordEvent(created, corev1.EventTypeNormal, reasonCreated, "Created Homer config")
+		return true, nil
 	}
 
 	// Skip update if content is unchanged.
-	if contentHash(existing.Data["config.yml"]) == hash {
-		slog.Debug("configmap already up to date", "namespace", ns, "name", name)
-		return nil
+	if c.upToDate("secret", ns, name, existing.ObjectMeta, string(existing.Data[key]), rendered) {
+		syncLog().Debug("secret already up to date", "namespace", ns, "name", name)
+		c.recordEvent(existing, corev1.EventTypeNormal, reasonUnchanged, "Homer config already up to date")
+		return false, nil
 	}
 
-	existing.Data = map[string]string{"config.yml": rendered}
-	if _, err := c.clients.Core.CoreV1().ConfigMaps(ns).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
-		return fmt.Errorf("update configmap %s/%s: %w", ns, name, err)
+	patch, err := dataPatch(map[string]interface{}{"data": map[string][]byte{key: []byte(rendered)}}, c.cfg.OutputLabels, c.outputAnnotations(hash))
+	if err != nil {
+		return false, err
 	}
-	slog.Info("updated configmap", "namespace", ns, "name", name)
-	return nil
+	updateCtx, cancelUpdate := c.apiContext(ctx)
+	updated, err := c.output().CoreV1().Secrets(ns).Patch(updateCtx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	cancelUpdate()
+	if err != nil {
+		return false, fmt.Errorf("patch secret %s/%s: %w", ns, name, err)
+	}
+	syncLog().Info("updated secret", "namespace", ns, "name", name)
+	c.recordEvent(updated, corev1.EventTypeNormal, reasonUpdated, "Updated Homer config")
+	return true, nil
 }
 
 // ---------------------------------------------------------------------------
 // Small utilities
 // ---------------------------------------------------------------------------
 
+// dataPatch builds a JSON merge patch setting only the given keys of the
+// data fields (data, binaryData), labels and annotations, leaving all others
+// alone; a nil value removes its key. []byte values are base64-encoded by
+// encoding/json, as Secret data and binaryData expect.
+func dataPatch(fields map[string]interface{}, labels, annotations map[string]string) ([]byte, error) {
+	meta := map[string]interface{}{"annotations": annotations}
+	if len(labels) > 0 {
+		meta["labels"] = labels
+	}
+	body := map[string]interface{}{"metadata": meta}
+	for k, v := range fields {
+		body[k] = v
+	}
+	patch, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("build data patch: %w", err)
+	}
+	return patch, nil
+}
+
+// contentHash hashes s without its generated-by header, so the header's
+// timestamp alone never counts as a change.
 func contentHash(s string) string {
-	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(stripHeader(s))))
 }
 
 func routeAnnotations(route map[string]interface{}) map[string]string {
@@ -431,6 +1843,11 @@ func routeAnnotations(route map[string]interface{}) map[string]string {
 	return ann
 }
 
+func routeLabels(route map[string]interface{}) map[string]string {
+	labels, _ := route["labels"].(map[string]string)
+	return labels
+}
+
 func stringOr(s, fallback string) string {
 	if s != "" {
 		return s
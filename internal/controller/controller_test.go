@@ -0,0 +1,321 @@
+package controller
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+func TestSelfExclusion(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "tools")
+	t.Setenv("POD_NAME", "homer-sync-7d9f8c6b5-x2x4q")
+	selfNS, selfName := config.DetectSelf()
+
+	tests := []struct {
+		name    string
+		exclude bool
+		ns      string
+		route   string
+		want    bool
+	}{
+		{name: "own route", exclude: true, ns: "tools", route: "homer-sync", want: false},
+		{name: "same name elsewhere", exclude: true, ns: "media", route: "homer-sync", want: true},
+		{name: "other route in own namespace", exclude: true, ns: "tools", route: "homer", want: true},
+		{name: "disabled", exclude: false, ns: "tools", route: "homer-sync", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.SelfExclude, cfg.SelfNamespace, cfg.SelfName = tt.exclude, selfNS, selfName
+			c, _ := newTestController(cfg, nil)
+			route := map[string]interface{}{
+				"namespace":   tt.ns,
+				"name":        tt.route,
+				"annotations": map[string]string{config.AnnotationPrefix + "/enabled": "true"},
+				"hostnames":   []string{"x.example.com"},
+			}
+			if got := c.shouldInclude(route, map[string]namespaceMeta{}); got != tt.want {
+				t.Errorf("shouldInclude(%s/%s) = %v, want %v", tt.ns, tt.route, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncKeepsOtherKeys(t *testing.T) {
+	cfg := testConfig()
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cfg.ConfigMapNamespace, Name: cfg.ConfigMapName},
+		Data:       map[string]string{cfg.ConfigMapKey: "stale", "custom.css": "body { color: red; }"},
+	}
+	c, cs := newTestController(cfg, []runtime.Object{existing, testNamespace("media", nil)},
+		testRoute("media", "jellyfin", nil, "jellyfin.example.com"))
+
+	if _, err := c.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+	cm, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), cfg.ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cm.Data[cfg.ConfigMapKey], "https://jellyfin.example.com") {
+		t.Errorf("config key not updated:\n%s", cm.Data[cfg.ConfigMapKey])
+	}
+	if got := cm.Data["custom.css"]; got != "body { color: red; }" {
+		t.Errorf("custom.css = %q, want it untouched", got)
+	}
+}
+
+func TestSyncCustomKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.ConfigMapKey = "homer.yml"
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cfg.ConfigMapNamespace, Name: cfg.ConfigMapName},
+		Data:       map[string]string{"config.yml": "hand-written", "custom.css": "body { color: red; }"},
+	}
+	c, cs := newTestController(cfg, []runtime.Object{existing, testNamespace("media", nil)},
+		testRoute("media", "jellyfin", nil, "jellyfin.example.com"))
+
+	if _, err := c.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+	cm, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), cfg.ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cm.Data["homer.yml"], "https://jellyfin.example.com") {
+		t.Errorf("custom key not written:\n%s", cm.Data["homer.yml"])
+	}
+	// Only the configured key is managed; the default one is left alone.
+	want := map[string]string{"config.yml": "hand-written", "custom.css": "body { color: red; }"}
+	for k, v := range want {
+		if cm.Data[k] != v {
+			t.Errorf("%s = %q, want it untouched (%q)", k, cm.Data[k], v)
+		}
+	}
+	if len(cm.Data) != 3 {
+		t.Errorf("configmap keys = %d, want 3", len(cm.Data))
+	}
+}
+
+func TestResolveGroupIconConflict(t *testing.T) {
+	p := config.AnnotationPrefix
+	nsMap := map[string]namespaceMeta{
+		"zeta":  {Annotations: map[string]string{p + "/group": "Media", p + "/group-icon": "fas fa-tv"}},
+		"alpha": {Annotations: map[string]string{p + "/group": "Media", p + "/group-icon": "fas fa-film"}},
+		"other": {Annotations: map[string]string{p + "/group-icon": "fas fa-box"}},
+	}
+	c, _ := newTestController(testConfig(), nil)
+	for i := 0; i < 20; i++ {
+		if got := c.resolveGroupIconForName("Media", nsMap, slices.Sorted(maps.Keys(nsMap))); got != "fas fa-film" {
+			t.Fatalf("resolveGroupIconForName = %q, want the icon of alpha", got)
+		}
+	}
+}
+
+func TestSummaryGroup(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	groups := []GroupData{
+		{Name: "Media", Items: []ServiceItem{{Name: "Plex", Created: day(3)}, {Name: "Jellyfin", Created: day(1)}}},
+		{Name: "Tools", Items: []ServiceItem{{Name: "Grafana", Created: day(2)}}},
+	}
+	tests := []struct {
+		name   string
+		sg     config.SummaryGroup
+		groups []GroupData
+		want   []string // nil when no summary group is built
+	}{
+		{name: "disabled", groups: groups},
+		{name: "all by name", sg: config.SummaryGroup{Mode: "all", Name: "All"}, groups: groups, want: []string{"Grafana", "Jellyfin", "Plex"}},
+		{name: "recent newest first", sg: config.SummaryGroup{Mode: "recent", Limit: 2, Name: "New"}, groups: groups, want: []string{"Plex", "Grafana"}},
+		{name: "recent over the count", sg: config.SummaryGroup{Mode: "recent", Limit: 5, Name: "New"}, groups: groups[1:], want: []string{"Grafana"}},
+		{name: "empty", sg: config.SummaryGroup{Mode: "all", Name: "All"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{cfg: testConfig()}
+			c.cfg.SummaryGroup = tt.sg
+			got, ok := c.summaryGroup(tt.groups)
+			if ok != (tt.want != nil) {
+				t.Fatalf("summaryGroup built = %v, want %v", ok, tt.want != nil)
+			}
+			var names []string
+			for _, it := range got.Items {
+				names = append(names, it.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("summary items = %v, want %v", names, tt.want)
+			}
+			if ok && got.Name != tt.sg.Name {
+				t.Errorf("summary name = %q, want %q", got.Name, tt.sg.Name)
+			}
+		})
+	}
+}
+
+func TestSummaryGroupPlacement(t *testing.T) {
+	p := config.AnnotationPrefix
+	cfg := testConfig()
+	cfg.SummaryGroup = config.SummaryGroup{Mode: "all", Name: "Everything"}
+	cfg.Order.GroupOrder = []string{"Tools"}
+	nsMap := map[string]namespaceMeta{
+		"media": {Annotations: map[string]string{p + "/group-sort": "-100"}},
+		"tools": {},
+	}
+	groups := map[string][]ServiceItem{
+		"Media": {{Namespace: "media", Name: "Plex", URL: "https://plex.example.com", Group: "Media"}},
+		"Tools": {{Namespace: "tools", Name: "Grafana", URL: "https://grafana.example.com", Group: "Tools"}},
+	}
+	c := &Controller{cfg: cfg}
+
+	// Neither --group-order nor a negative group-sort moves a group above
+	// the pinned summary group.
+	out, err := c.buildTemplateData(groups, nsMap, slices.Sorted(maps.Keys(nsMap)), nil, singleTemplate(builtinTemplate("v1")), 0)
+	if err != nil {
+		t.Fatalf("buildTemplateData: %v", err)
+	}
+	summary := strings.Index(out, `name: "Everything"`)
+	for _, g := range []string{`name: "Tools"`, `name: "Media"`} {
+		if i := strings.Index(out, g); summary < 0 || i < summary {
+			t.Errorf("group %s rendered before the summary group:\n%s", g, out)
+		}
+	}
+}
+
+func TestMaintenanceBanner(t *testing.T) {
+	src := config.MaintenanceSource{Namespace: "default", Name: "maintenance", Key: "message", Style: "is-warning", Title: "Maintenance"}
+	banner := func(content string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: src.Namespace, Name: src.Name},
+			Data:       map[string]string{src.Key: content},
+		}
+	}
+	tests := []struct {
+		name   string
+		source config.MaintenanceSource
+		cm     *corev1.ConfigMap
+		want   bool
+	}{
+		{name: "source set", source: src, cm: banner("NAS reboot at 22:00"), want: true},
+		{name: "source unset", cm: banner("NAS reboot at 22:00")},
+		{name: "configmap missing", source: src},
+		{name: "key empty", source: src, cm: banner("  ")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Maintenance = tt.source
+			core := []runtime.Object{testNamespace("media", nil)}
+			if tt.cm != nil {
+				core = append(core, tt.cm)
+			}
+			c, cs := newTestController(cfg, core, testRoute("media", "plex", nil, "plex.example.com"))
+			if _, err := c.runOnce(context.Background()); err != nil {
+				t.Fatalf("runOnce: %v", err)
+			}
+			out := outputConfig(t, cs, cfg)
+			if got := strings.Contains(out, "\nmessage:"); got != tt.want {
+				t.Errorf("message block present = %v, want %v:\n%s", got, tt.want, out)
+			}
+			if tt.want && !strings.Contains(out, `content: "NAS reboot at 22:00"`) {
+				t.Errorf("message content missing:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestSplitGroupPath(t *testing.T) {
+	tests := []struct {
+		group, parent, sub string
+	}{
+		{group: "Media", parent: "Media"},
+		{group: "Infra/Monitoring", parent: "Infra", sub: "Monitoring"},
+		{group: "a/b/c", parent: "a", sub: "b/c"},
+		{group: " Infra / Monitoring ", parent: "Infra", sub: "Monitoring"},
+		{group: "Infra//Monitoring", parent: "Infra", sub: "Monitoring"},
+		{group: "a/ /b/ c /", parent: "a", sub: "b/c"},
+		{group: "/Monitoring", parent: "Monitoring"},
+		{group: "Infra/", parent: "Infra"},
+		{group: "/"},
+	}
+	for _, tt := range tests {
+		parent, sub := splitGroupPath(tt.group)
+		if parent != tt.parent || sub != tt.sub {
+			t.Errorf("splitGroupPath(%q) = %q, %q; want %q, %q", tt.group, parent, sub, tt.parent, tt.sub)
+		}
+	}
+}
+
+func TestGroupPathFlattened(t *testing.T) {
+	c := &Controller{cfg: testConfig()}
+	groups := map[string][]ServiceItem{
+		" a / b / c ": {{Name: "Grafana", URL: "https://grafana.example.com", Group: " a / b / c "}},
+		"Media":       {{Name: "Plex", URL: "https://plex.example.com", Group: "Media"}},
+	}
+	out, err := c.buildTemplateData(groups, nil, nil, nil, singleTemplate(builtinTemplate("v1")), 0)
+	if err != nil {
+		t.Fatalf("buildTemplateData: %v", err)
+	}
+	for _, want := range []string{`name: "a — b/c"`, `name: "Media"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rendered config lacks %s:\n%s", want, out)
+		}
+	}
+}
+
+// TestTagPrecedence pins where an item's tag comes from: the route's own
+// annotation, then its backend Service's (--read-backend-annotations), then
+// the namespace label rules (--label-to-tag).
+func TestTagPrecedence(t *testing.T) {
+	p := config.AnnotationPrefix
+	tests := []struct {
+		name     string
+		routeAnn map[string]string
+		svcAnn   map[string]string
+		want     string
+	}{
+		{name: "label rule", want: `tag: "prod"` + "\n" + `        tagstyle: "is-danger"`},
+		{name: "backend beats label rule", svcAnn: map[string]string{p + "/tag": "svc", p + "/tagstyle": "is-info"},
+			want: `tag: "svc"` + "\n" + `        tagstyle: "is-info"`},
+		{name: "route beats backend", routeAnn: map[string]string{p + "/tag": "route", p + "/tagstyle": "is-success"},
+			svcAnn: map[string]string{p + "/tag": "svc", p + "/tagstyle": "is-info"},
+			want:   `tag: "route"` + "\n" + `        tagstyle: "is-success"`},
+		{name: "keys merge individually", routeAnn: map[string]string{p + "/tag": "route"},
+			svcAnn: map[string]string{p + "/tagstyle": "is-info"},
+			want:   `tag: "route"` + "\n" + `        tagstyle: "is-info"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.NoHeader = true
+			cfg.BackendAnnotations = true
+			cfg.LabelTags = []config.LabelTag{{Label: "env", Value: "prod", TagStyle: "is-danger"}}
+
+			ns := testNamespace("media", nil)
+			ns.Labels = map[string]string{"env": "prod"}
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "media", Name: "plex", Annotations: tt.svcAnn}}
+			route := testRoute("media", "plex", tt.routeAnn, "plex.example.com")
+			route.Spec.Rules = []gwv1.HTTPRouteRule{{BackendRefs: []gwv1.HTTPBackendRef{{
+				BackendRef: gwv1.BackendRef{BackendObjectReference: gwv1.BackendObjectReference{Name: "plex"}},
+			}}}}
+
+			c, _ := newTestController(cfg, []runtime.Object{ns, svc}, route)
+			out, err := c.Render(context.Background())
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !strings.Contains(out[cfg.ConfigMapName], tt.want) {
+				t.Errorf("rendered config lacks\n%s\n in:\n%s", tt.want, out[cfg.ConfigMapName])
+			}
+		})
+	}
+}
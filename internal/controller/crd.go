@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// requiredAPI is a CRD-backed resource one of the enabled sources lists.
+type requiredAPI struct {
+	Kind         string
+	GroupVersion string
+	Resource     string
+	Hint         string
+}
+
+// MissingAPIError reports that the cluster does not serve a resource an
+// enabled source needs, typically because its CRDs are not installed.
+type MissingAPIError struct {
+	API requiredAPI
+}
+
+func (e *MissingAPIError) Error() string {
+	return fmt.Sprintf("%s (%s) is not served by the cluster: %s", e.API.Kind, e.API.GroupVersion, e.API.Hint)
+}
+
+// requiredAPIs lists the CRD-backed resources of the configured sources.
+func (c *Controller) requiredAPIs() []requiredAPI {
+	var apis []requiredAPI
+	if slices.Contains(c.cfg.Sources, "httproute") {
+		apis = append(apis, gatewayAPI("HTTPRoute", "gateway.networking.k8s.io/v1", "httproutes", "httproute", "--sources"))
+	}
+	if slices.Contains(c.cfg.Sources, "openshift-route") {
+		apis = append(apis, requiredAPI{
+			Kind: "Route", GroupVersion: "route.openshift.io/v1", Resource: "routes",
+			Hint: "this does not look like an OpenShift cluster; remove openshift-route from --sources",
+		})
+	}
+	if slices.Contains(c.cfg.RouteKinds, "grpcroute") {
+		apis = append(apis, gatewayAPI("GRPCRoute", "gateway.networking.k8s.io/v1", "grpcroutes", "grpcroute", "--route-kinds"))
+	}
+	if slices.Contains(c.cfg.RouteKinds, "tcproute") {
+		apis = append(apis, gatewayAPI("TCPRoute", "gateway.networking.k8s.io/v1alpha2", "tcproutes", "tcproute", "--route-kinds"))
+	}
+	return apis
+}
+
+func gatewayAPI(kind, gv, resource, source, flag string) requiredAPI {
+	return requiredAPI{
+		Kind: kind, GroupVersion: gv, Resource: resource,
+		Hint: fmt.Sprintf("install the Gateway API CRDs (https://gateway-api.sigs.k8s.io/guides/#installing-gateway-api) or remove %s from %s", source, flag),
+	}
+}
+
+// apiByKind returns the required API entry for kind.
+func (c *Controller) apiByKind(kind string) (requiredAPI, bool) {
+	for _, api := range c.requiredAPIs() {
+		if api.Kind == kind {
+			return api, true
+		}
+	}
+	return requiredAPI{}, false
+}
+
+// missingAPI turns a List error caused by an unserved resource into a
+// MissingAPIError for kind; other errors are returned unchanged.
+func (c *Controller) missingAPI(err error, kind string) error {
+	if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return err
+	}
+	if api, ok := c.apiByKind(kind); ok {
+		return &MissingAPIError{API: api}
+	}
+	return err
+}
+
+// preflight checks via discovery that every CRD-backed resource of the
+// enabled sources is served, so a missing install is reported once at startup
+// instead of as an opaque List failure on every scan.
+func (c *Controller) preflight(ctx context.Context) error {
+	for _, api := range c.requiredAPIs() {
+		list, err := c.serverResources(ctx, api.GroupVersion)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("discover %s: %w", api.GroupVersion, err)
+		}
+		served := false
+		if list != nil {
+			for _, r := range list.APIResources {
+				if r.Name == api.Resource {
+					served = true
+					break
+				}
+			}
+		}
+		if !served {
+			return &MissingAPIError{API: api}
+		}
+	}
+	return nil
+}
+
+// logMissingAPI logs a prominent, actionable hint when err is caused by a
+// missing API and reports whether it was.
+func logMissingAPI(err error) bool {
+	var missing *MissingAPIError
+	if !errors.As(err, &missing) {
+		return false
+	}
+	ctrlLog().Error("required API not installed; scans will keep failing until it is",
+		"kind", missing.API.Kind, "group_version", missing.API.GroupVersion, "hint", missing.API.Hint)
+	return true
+}
+
+// serverResources asks discovery for the resources of gv, giving up after
+// --api-timeout. The discovery client takes no context, so the request runs in
+// its own goroutine and is abandoned, not cancelled, on timeout.
+func (c *Controller) serverResources(ctx context.Context, gv string) (*metav1.APIResourceList, error) {
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+	type result struct {
+		list *metav1.APIResourceList
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		list, err := c.clients.Core.Discovery().ServerResourcesForGroupVersion(gv)
+		done <- result{list, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.list, r.err
+	}
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPreflightMissingAPI(t *testing.T) {
+	cfg := testConfig()
+	cfg.RouteKinds = []string{"grpcroute"}
+	c, _ := newTestController(cfg, nil)
+
+	err := c.preflight(context.Background())
+	var missing *MissingAPIError
+	if !errors.As(err, &missing) || missing.API.Kind != "GRPCRoute" {
+		t.Fatalf("preflight = %v, want a MissingAPIError for GRPCRoute", err)
+	}
+}
+
+func TestPreflightTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.APITimeout = 1
+	c, cs := newTestController(cfg, nil)
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+	cs.PrependReactor("get", "resource", func(k8stesting.Action) (bool, runtime.Object, error) {
+		<-unblock
+		return false, nil, nil
+	})
+
+	start := time.Now()
+	err := c.preflight(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("preflight = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("preflight took %s with a 1s --api-timeout", elapsed)
+	}
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// hashAnnotation records the content hash homer-sync last wrote to the output
+// object, so an edit made by someone else can be told apart from a stale
+// render.
+const hashAnnotation = config.AnnotationPrefix + "/content-hash"
+
+// upToDate reports whether the live content of an output object already
+// matches rendered, so the write can be skipped. It warns when the live
+// content no longer matches the hash homer-sync last wrote (a hand edit). An
+// object without the hash annotation, or missing a configured label or
+// annotation, is written once to gain it, and --force-sync always writes.
+func (c *Controller) upToDate(kind, ns, name string, meta metav1.ObjectMeta, live, rendered string) bool {
+	liveHash := contentHash(live)
+	last, ok := meta.Annotations[hashAnnotation]
+	if ok && last != liveHash {
+		syncLog().Warn(kind+" content drifted from last sync", "namespace", ns, "name", name)
+	}
+	if c.cfg.ForceSync {
+		return false
+	}
+	return ok && last == liveHash && liveHash == contentHash(rendered) && c.outputMetaUpToDate(meta)
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+func TestUpToDate(t *testing.T) {
+	hashed := func(content string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Annotations: map[string]string{hashAnnotation: contentHash(content)}}
+	}
+	tests := []struct {
+		name     string
+		force    bool
+		labels   map[string]string
+		meta     metav1.ObjectMeta
+		live     string
+		rendered string
+		want     bool
+	}{
+		{name: "unchanged", meta: hashed("a"), live: "a", rendered: "a", want: true},
+		{name: "new render", meta: hashed("a"), live: "a", rendered: "b", want: false},
+		{name: "no hash annotation", live: "a", rendered: "a", want: false},
+		{name: "hand edit reverted by render", meta: hashed("a"), live: "edited", rendered: "edited", want: false},
+		{name: "force sync", force: true, meta: hashed("a"), live: "a", rendered: "a", want: false},
+		{name: "missing output label", labels: map[string]string{"app": "homer"}, meta: hashed("a"), live: "a", rendered: "a", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{cfg: &config.Config{ForceSync: tt.force, OutputLabels: tt.labels}}
+			if got := c.upToDate("configmap", "default", "homer-config", tt.meta, tt.live, tt.rendered); got != tt.want {
+				t.Errorf("upToDate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
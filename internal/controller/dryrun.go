@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// printDryRun writes the rendered config and, when the target object exists, a
+// line diff against its current content. Nothing is written to the cluster.
+func printDryRun(w io.Writer, kind, ns, name string, exists bool, current, rendered string) error {
+	syncLog().Info("dry run: skipping write", "kind", kind, "namespace", ns, "name", name)
+
+	if _, err := fmt.Fprintf(w, "# --- rendered config for %s %s/%s ---\n%s\n", kind, ns, name, rendered); err != nil {
+		return fmt.Errorf("print rendered config: %w", err)
+	}
+
+	var diff string
+	switch {
+	case !exists:
+		diff = fmt.Sprintf("# %s %s/%s does not exist and would be created\n", kind, ns, name)
+	case stripHeader(current) == stripHeader(rendered):
+		diff = fmt.Sprintf("# %s %s/%s is up to date\n", kind, ns, name)
+	default:
+		diff = fmt.Sprintf("# --- diff against %s %s/%s ---\n%s", kind, ns, name, lineDiff(current, rendered))
+	}
+	if _, err := io.WriteString(w, diff); err != nil {
+		return fmt.Errorf("print diff: %w", err)
+	}
+	return nil
+}
+
+// lineDiff returns a minimal line-based diff of a → b, prefixing removed lines
+// with "-", added lines with "+" and unchanged lines with " ". It uses a plain
+// LCS table, which is plenty for dashboard-sized configs.
+func lineDiff(a, b string) string {
+	x := strings.Split(a, "\n")
+	y := strings.Split(b, "\n")
+
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			sb.WriteString(" " + x[i] + "\n")
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			sb.WriteString("-" + x[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+" + y[j] + "\n")
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		sb.WriteString("-" + x[i] + "\n")
+	}
+	for ; j < len(y); j++ {
+		sb.WriteString("+" + y[j] + "\n")
+	}
+	return sb.String()
+}
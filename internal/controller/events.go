@@ -0,0 +1,38 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Event reasons emitted against the output object when --emit-events is set.
+const (
+	reasonCreated      = "Created"
+	reasonUpdated      = "Updated"
+	reasonUnchanged    = "Unchanged"
+	reasonRenderFailed = "RenderFailed"
+)
+
+// recordEvent emits a Kubernetes Event for obj. It is a no-op unless events
+// are enabled.
+func (c *Controller) recordEvent(obj runtime.Object, eventType, reason, format string, args ...interface{}) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder.Eventf(obj, eventType, reason, format, args...)
+}
+
+// outputRef references an output object by name, for events about objects
+// that may not exist yet (e.g. a render failure before the first write).
+func (c *Controller) outputRef(name string) *corev1.ObjectReference {
+	kind := "ConfigMap"
+	if c.cfg.OutputKind == "secret" {
+		kind = "Secret"
+	}
+	return &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       kind,
+		Namespace:  c.cfg.ConfigMapNamespace,
+		Name:       name,
+	}
+}
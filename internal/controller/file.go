@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// outputFilePath returns where the output named name is written in
+// --output-file mode. The primary output uses the configured path; extra
+// outputs from --configmap-map go next to it as "<name><ext>".
+func (c *Controller) outputFilePath(name string) string {
+	if name == c.cfg.ConfigMapName {
+		return c.cfg.OutputFile
+	}
+	ext := filepath.Ext(c.cfg.OutputFile)
+	if ext == "" {
+		ext = ".yml"
+	}
+	return filepath.Join(filepath.Dir(c.cfg.OutputFile), name+ext)
+}
+
+// syncFile writes rendered to the file of the output named name, or with
+// --dry-run prints it and its diff against the current file instead.
+func (c *Controller) syncFile(name, rendered string) (bool, error) {
+	path := c.outputFilePath(name)
+	if c.cfg.DryRun {
+		current, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("read %s: %w", path, err)
+		}
+		return false, printDryRun(os.Stdout, "file", filepath.Dir(path), filepath.Base(path), err == nil, string(current), rendered)
+	}
+	if err := writeOutputFile(path, rendered); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeOutputFile atomically replaces path with rendered by writing a temp file
+// in the same directory and renaming it over the target, so readers never see
+// a partially written config.
+func writeOutputFile(path, rendered string) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file in %s: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.WriteString(rendered); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("chmod %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename %s to %s: %w", tmp.Name(), path, err)
+	}
+
+	syncLog().Info("wrote config file", "path", path, "bytes", len(rendered))
+	return nil
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestOutputFile(t *testing.T) {
+	core := []runtime.Object{testNamespace("media", nil)}
+	route := testRoute("media", "app", nil, "app.example.com")
+
+	tests := []struct {
+		name      string
+		dryRun    bool
+		existing  string
+		wantFile  string
+		wantWrite bool
+	}{
+		{name: "writes the file", wantWrite: true},
+		{name: "dry run leaves a missing file alone", dryRun: true},
+		{name: "dry run leaves an existing file alone", dryRun: true, existing: "old: config\n", wantFile: "old: config\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := testConfig()
+			cfg.OutputFile, cfg.DryRun = path, tt.dryRun
+			c, cs := newTestController(cfg, core, route)
+			if _, err := c.runOnce(context.Background()); err != nil {
+				t.Fatalf("runOnce: %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			switch {
+			case tt.wantWrite:
+				if err != nil || !strings.Contains(string(got), "app.example.com") {
+					t.Errorf("file = %q, %v; want the rendered config", got, err)
+				}
+			case tt.existing != "":
+				if string(got) != tt.wantFile {
+					t.Errorf("file = %q, want it untouched (%q)", got, tt.wantFile)
+				}
+			default:
+				if !os.IsNotExist(err) {
+					t.Errorf("file exists after a dry run: %q, %v", got, err)
+				}
+			}
+			if configHas(cs, cfg, "") {
+				t.Error("output-file mode wrote a ConfigMap")
+			}
+		})
+	}
+}
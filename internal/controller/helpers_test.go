@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwfake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+	"github.com/mirceanton/homer-sync/internal/k8s"
+)
+
+// testConfig returns the CLI defaults that matter for the built-in template,
+// writing a ConfigMap default/homer-config.
+func testConfig() *config.Config {
+	return &config.Config{
+		Sources:            []string{"httproute"},
+		ConfigMapName:      "homer-config",
+		ConfigMapNamespace: "default",
+		ConfigMapKey:       "config.yml",
+		OutputKind:         "configmap",
+		ApplyMode:          "update",
+		OnDuplicate:        "warn",
+		Title:              "Home",
+		Columns:            3,
+		DefaultTarget:      "_blank",
+		Order:              config.DefaultOrderPolicy(),
+	}
+}
+
+// testNamespace returns a namespace with the given annotations.
+func testNamespace(name string, ann map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: ann}}
+}
+
+// testRoute returns an enabled HTTPRoute with the given hostnames; ann is
+// added to the enabled annotation.
+func testRoute(ns, name string, ann map[string]string, hosts ...string) *gwv1.HTTPRoute {
+	all := map[string]string{config.AnnotationPrefix + "/enabled": "true"}
+	for k, v := range ann {
+		all[k] = v
+	}
+	r := &gwv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         ns,
+		Name:              name,
+		Annotations:       all,
+		CreationTimestamp: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}}
+	for _, h := range hosts {
+		r.Spec.Hostnames = append(r.Spec.Hostnames, gwv1.Hostname(h))
+	}
+	return r
+}
+
+// newTestController returns a controller over fake clients holding core
+// objects and Gateway API routes, with HTTPRoutes served by discovery.
+func newTestController(cfg *config.Config, core []runtime.Object, routes ...runtime.Object) (*Controller, *fake.Clientset) {
+	cs := fake.NewClientset(core...)
+	cs.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "gateway.networking.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "httproutes"}},
+	}}
+	gw := gwfake.NewSimpleClientset(routes...)
+	return New(&k8s.Clients{Core: cs, Gateway: gw}, cfg), cs
+}
+
+// outputConfig returns the config key of the output ConfigMap, failing the
+// test when it does not exist.
+func outputConfig(t *testing.T, cs *fake.Clientset, cfg *config.Config) string {
+	t.Helper()
+	cm, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), cfg.ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get output configmap: %v", err)
+	}
+	return cm.Data[cfg.ConfigMapKey]
+}
+
+// configHas reports whether the output ConfigMap exists and contains want.
+func configHas(cs *fake.Clientset, cfg *config.Config, want string) bool {
+	cm, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), cfg.ConfigMapName, metav1.GetOptions{})
+	return err == nil && strings.Contains(cm.Data[cfg.ConfigMapKey], want)
+}
+
+// waitFor polls cond until it holds, failing the test after five seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+func TestPreferredHostname(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefer    []string
+		shortest  bool
+		hostnames []string
+		want      string
+	}{
+		{name: "no policy keeps first", hostnames: []string{"app.lan", "app.example.com"}, want: "app.lan"},
+		{name: "suffix match first", prefer: []string{".example.com"}, hostnames: []string{"app.example.com", "app.lan"}, want: "app.example.com"},
+		{name: "suffix match last", prefer: []string{".example.com"}, hostnames: []string{"app.lan", "*.apps.lan", "app.example.com"}, want: "app.example.com"},
+		{name: "suffix match middle", prefer: []string{".example.com"}, hostnames: []string{"*.apps.lan", "app.example.com", "app.lan"}, want: "app.example.com"},
+		{name: "earlier suffix wins", prefer: []string{".example.com", ".lan"}, hostnames: []string{"app.lan", "app.example.com"}, want: "app.example.com"},
+		{name: "later suffix used when earlier is absent", prefer: []string{".example.com", ".lan"}, hostnames: []string{"app.internal", "app.lan"}, want: "app.lan"},
+		{name: "no suffix matches falls back to first", prefer: []string{".example.com"}, hostnames: []string{"app.lan", "app.internal"}, want: "app.lan"},
+		{name: "shortest", shortest: true, hostnames: []string{"app.internal.example.com", "app.lan"}, want: "app.lan"},
+		{name: "shortest reversed", shortest: true, hostnames: []string{"app.lan", "app.internal.example.com"}, want: "app.lan"},
+		{name: "shortest tie keeps spec order", shortest: true, hostnames: []string{"b.lan", "a.lan"}, want: "b.lan"},
+		{name: "shortest among suffix matches", prefer: []string{".example.com"}, shortest: true, hostnames: []string{"a.lan", "app.internal.example.com", "app.example.com"}, want: "app.example.com"},
+		{name: "none", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{cfg: &config.Config{PreferSuffixes: tt.prefer, PreferShortest: tt.shortest}}
+			if got := c.preferredHostname(tt.hostnames); got != tt.want {
+				t.Errorf("preferredHostname(%v) = %q, want %q", tt.hostnames, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropExcludedHostnames(t *testing.T) {
+	tests := []struct {
+		name      string
+		exclude   []string
+		hostnames []string
+		want      []string
+		wantOK    bool
+	}{
+		{name: "no denylist", hostnames: []string{"a.lan"}, want: []string{"a.lan"}, wantOK: true},
+		{name: "exact name", exclude: []string{"a.lan"}, hostnames: []string{"a.lan", "a.example.com"}, want: []string{"a.example.com"}, wantOK: true},
+		{name: "glob", exclude: []string{"*.lan"}, hostnames: []string{"a.lan", "a.example.com", "b.lan"}, want: []string{"a.example.com"}, wantOK: true},
+		{name: "trailing dot", exclude: []string{"a.lan"}, hostnames: []string{"a.lan.", "a.example.com"}, want: []string{"a.example.com"}, wantOK: true},
+		{name: "all excluded", exclude: []string{"*.lan"}, hostnames: []string{"a.lan", "b.lan"}, want: []string{"a.lan", "b.lan"}, wantOK: false},
+		{name: "no hostnames", exclude: []string{"*.lan"}, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{cfg: &config.Config{ExcludeHostnames: tt.exclude}}
+			route := map[string]interface{}{"namespace": "media", "name": "app", "hostnames": tt.hostnames}
+			got, ok := c.dropExcludedHostnames(route)
+			hostnames, _ := got["hostnames"].([]string)
+			if ok != tt.wantOK || !reflect.DeepEqual(hostnames, tt.want) {
+				t.Errorf("dropExcludedHostnames(%v) = %v, %v; want %v, %v", tt.hostnames, hostnames, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestExcludePreferredHostname checks that excluding the hostname the
+// preference policy would pick links the route to the best remaining one, and
+// that a route left without hostnames is skipped.
+func TestExcludePreferredHostname(t *testing.T) {
+	cfg := testConfig()
+	cfg.PreferSuffixes = []string{".internal.lan"}
+	cfg.ExcludeHostnames = []string{"*.internal.lan"}
+	c, _ := newTestController(cfg, []runtime.Object{testNamespace("media", nil)},
+		testRoute("media", "app", nil, "app.internal.lan", "app.example.com", "app.example.org"),
+		testRoute("media", "private", nil, "private.internal.lan"),
+	)
+
+	configs, err := c.Render(context.Background())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	rendered := configs[cfg.ConfigMapName]
+	if !strings.Contains(rendered, "https://app.example.com") {
+		t.Errorf("route not linked to the first remaining hostname:\n%s", rendered)
+	}
+	for _, notWant := range []string{"internal.lan", "app.example.org"} {
+		if strings.Contains(rendered, notWant) {
+			t.Errorf("rendered config contains %q:\n%s", notWant, rendered)
+		}
+	}
+}
+
+func TestHostMatchesSuffix(t *testing.T) {
+	tests := []struct {
+		host, suffix string
+		want         bool
+	}{
+		{host: "app.example.com", suffix: "*.example.com", want: true},
+		{host: "app.example.com.", suffix: "*.example.com", want: true},
+		{host: "app.example.com", suffix: "*.example.com.", want: true},
+		{host: "app.example.com.", suffix: "example.com", want: true},
+		{host: "a.b.example.com", suffix: "*.example.com", want: true},
+		{host: "a.b.example.com", suffix: "*.*.example.com", want: true},
+		{host: "a.example.com", suffix: "*.*.example.com", want: false},
+		{host: "example.com", suffix: "*.example.com", want: false},
+		{host: "example.com.", suffix: "*.example.com", want: false},
+		{host: "app.example.org", suffix: "*.example.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := hostMatchesSuffix(tt.host, tt.suffix); got != tt.want {
+			t.Errorf("hostMatchesSuffix(%q, %q) = %v, want %v", tt.host, tt.suffix, got, tt.want)
+		}
+	}
+}
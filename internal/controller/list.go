@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+)
+
+// List scans the cluster and writes every dashboard item to w, one row each,
+// without rendering or writing anything. Hidden items are listed as present
+// but hidden, unlike routes excluded by the filters, which are only counted.
+func (c *Controller) List(ctx context.Context, w io.Writer) error {
+	var sum ScanSummary
+	res, err := c.scan(ctx, &sum)
+	if err != nil {
+		return err
+	}
+	items := res.items
+	slices.SortStableFunc(items, func(a, b ServiceItem) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), compareItems(a, b, c.cfg.Order.ItemKeys))
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tNAME\tNAMESPACE\tROUTE\tURL\tSTATE")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", item.Group, item.Name, item.Namespace, item.Route, item.URL, itemState(item))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n%d items (%d hidden) from %d routes; %d filtered out, %d without a link\n",
+		sum.Included, sum.Hidden, sum.Routes, sum.Filtered, sum.Skipped)
+	return err
+}
+
+// itemState describes how an item appears on the dashboard.
+func itemState(item ServiceItem) string {
+	switch {
+	case item.Hidden:
+		return "hidden"
+	case item.Unsearchable:
+		return "shown, unsearchable"
+	default:
+		return "shown"
+	}
+}
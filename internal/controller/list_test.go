@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// TestHiddenVersusExcluded checks that a hidden route stays in the model
+// (listed and counted) but gets no card, while an excluded one is dropped.
+func TestHiddenVersusExcluded(t *testing.T) {
+	p := config.AnnotationPrefix
+	core := []runtime.Object{testNamespace("media", nil)}
+	routes := []runtime.Object{
+		testRoute("media", "shown", nil, "shown.example.com"),
+		testRoute("media", "secret-admin", map[string]string{p + "/hidden": "true"}, "admin.example.com"),
+		testRoute("media", "excluded", map[string]string{p + "/enabled": "false"}, "excluded.example.com"),
+		testRoute("media", "quiet", map[string]string{p + "/unsearchable": "true", p + "/keywords": "x"}, "quiet.example.com"),
+	}
+	cfg := testConfig()
+
+	c, _ := newTestController(cfg, core, routes...)
+	configs, err := c.Render(context.Background())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	rendered := configs[cfg.ConfigMapName]
+	for _, want := range []string{"shown.example.com", "quiet.example.com", "searchable: false"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered config lacks %q:\n%s", want, rendered)
+		}
+	}
+	for _, notWant := range []string{"admin.example.com", "excluded.example.com", `keywords: "x"`} {
+		if strings.Contains(rendered, notWant) {
+			t.Errorf("rendered config contains %q:\n%s", notWant, rendered)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := c.List(context.Background(), &out); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	listed := out.String()
+	if !strings.Contains(listed, "secret-admin") || !strings.Contains(listed, "hidden") {
+		t.Errorf("hidden item not listed as hidden:\n%s", listed)
+	}
+	if strings.Contains(listed, "excluded.example.com") {
+		t.Errorf("excluded route listed:\n%s", listed)
+	}
+	if !strings.Contains(listed, "3 items (1 hidden) from 4 routes; 1 filtered out") {
+		t.Errorf("unexpected counts:\n%s", listed)
+	}
+}
@@ -0,0 +1,23 @@
+package controller
+
+import "log/slog"
+
+// Values of the component attribute on every log record of this package, so
+// the scan loop can be told apart from the writes it triggers.
+const (
+	componentController = "controller"
+	componentSync       = "sync"
+)
+
+// ctrlLog returns the default logger tagged as the controller component: the
+// scan loop, route sources and item extraction. The default logger is looked
+// up on every call so that it picks up the handler installed at startup.
+func ctrlLog() *slog.Logger {
+	return slog.Default().With("component", componentController)
+}
+
+// syncLog returns the default logger tagged as the sync component: writing
+// the rendered output and the status ConfigMap.
+func syncLog() *slog.Logger {
+	return slog.Default().With("component", componentSync)
+}
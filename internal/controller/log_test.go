@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestLogComponent checks that every record of a scan carries a component
+// attribute, and that the scan and the write are told apart.
+func TestLogComponent(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(prev)
+
+	c, _ := newTestController(testConfig(), []runtime.Object{testNamespace("media", nil)},
+		testRoute("media", "jellyfin", nil, "jellyfin.example.com"))
+	if _, err := c.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+
+	components := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+		msg, _ := rec["msg"].(string)
+		comp, ok := rec["component"].(string)
+		if !ok {
+			t.Errorf("record %q has no component", msg)
+		}
+		components[msg] = comp
+	}
+	for msg, want := range map[string]string{"starting scan": componentController, "created configmap": componentSync} {
+		if got := components[msg]; got != want {
+			t.Errorf("%q logged with component %q, want %q", msg, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// outputAnnotations returns the annotations homer-sync sets on every output
+// object: --configmap-annotations plus the content hash of what it writes.
+func (c *Controller) outputAnnotations(hash string) map[string]string {
+	ann := make(map[string]string, len(c.cfg.OutputAnnotations)+1)
+	for k, v := range c.cfg.OutputAnnotations {
+		ann[k] = v
+	}
+	ann[hashAnnotation] = hash
+	return ann
+}
+
+// outputMetaUpToDate reports whether meta already carries every
+// --configmap-labels and --configmap-annotations entry. Labels and
+// annotations set by anyone else are ignored.
+func (c *Controller) outputMetaUpToDate(meta metav1.ObjectMeta) bool {
+	for k, v := range c.cfg.OutputLabels {
+		if cur, ok := meta.Labels[k]; !ok || cur != v {
+			return false
+		}
+	}
+	for k, v := range c.cfg.OutputAnnotations {
+		if cur, ok := meta.Annotations[k]; !ok || cur != v {
+			return false
+		}
+	}
+	return true
+}
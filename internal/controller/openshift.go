@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// openShiftRouteGVR identifies route.openshift.io/v1 Routes. They are read via
+// the dynamic client so non-OpenShift builds need no extra clientset.
+var openShiftRouteGVR = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+// fetchOpenShiftRoutes lists OpenShift Routes. spec.host becomes the single
+// hostname, and the link scheme follows spec.tls: https when a TLS termination
+// is configured, http otherwise (the scheme annotation still wins).
+func (c *Controller) fetchOpenShiftRoutes(ctx context.Context) ([]map[string]interface{}, error) {
+	list, err := listWithRetry(ctx, c, "openshift routes", func(ctx context.Context) (*unstructured.UnstructuredList, error) {
+		return c.clients.Dynamic.Resource(openShiftRouteGVR).Namespace("").List(ctx, c.routeListOptions())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list openshift routes: %w", c.missingAPI(err, "Route"))
+	}
+
+	routes := make([]map[string]interface{}, 0, len(list.Items))
+	for _, r := range list.Items {
+		var hostnames []string
+		if host, _, _ := unstructured.NestedString(r.Object, "spec", "host"); host != "" {
+			hostnames = append(hostnames, host)
+		}
+
+		scheme := "http"
+		if termination, _, _ := unstructured.NestedString(r.Object, "spec", "tls", "termination"); termination != "" {
+			scheme = "https"
+		}
+
+		ann := r.GetAnnotations()
+		if ann == nil {
+			ann = make(map[string]string)
+		}
+
+		routes = append(routes, map[string]interface{}{
+			"kind":              "Route",
+			"namespace":         r.GetNamespace(),
+			"name":              r.GetName(),
+			"annotations":       ann,
+			"labels":            r.GetLabels(),
+			"parentRefs":        []map[string]interface{}{},
+			"hostnames":         hostnames,
+			"scheme":            scheme,
+			"creationTimestamp": r.GetCreationTimestamp().Time,
+		})
+	}
+	return routes, nil
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// compareItems orders two items by the given keys in turn, then by URL and
+// finally by source namespace and route name, so items pulled into one group
+// from several namespaces (e.g. via a group override) order the same on every
+// scan regardless of API list order:
+//
+//   - sort:     ascending home.mirceanton.com/sort value
+//   - sort-key: ascending home.mirceanton.com/sort-key string; items without
+//     one compare equal, so it only breaks ties left by earlier keys
+//   - name:     ascending display name
+//   - url:      ascending link
+//   - created:  newest route first
+func compareItems(a, b ServiceItem, keys []string) int {
+	for _, k := range keys {
+		var r int
+		switch k {
+		case "sort":
+			r = cmp.Compare(a.Sort, b.Sort)
+		case "sort-key":
+			r = cmp.Compare(a.SortKey, b.SortKey)
+		case "name":
+			r = cmp.Compare(a.Name, b.Name)
+		case "url":
+			r = cmp.Compare(a.URL, b.URL)
+		case "created":
+			r = b.Created.Compare(a.Created)
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	return cmp.Or(
+		cmp.Compare(a.URL, b.URL),
+		cmp.Compare(a.Namespace, b.Namespace),
+		cmp.Compare(a.Route, b.Route),
+	)
+}
+
+// compareGroups orders two groups. Groups named in explicit come first, in
+// that order; the rest follow, compared by the given keys in turn and finally
+// by name and sub-group, so only groups whose paths trim to the same name ever
+// tie:
+//
+//   - sort: ascending home.mirceanton.com/group-sort namespace value
+//   - name: ascending group name, then sub-group (mirrors Jinja2's dictsort)
+func compareGroups(a, b GroupData, explicit, keys []string) int {
+	if r := cmp.Compare(explicitRank(a, explicit), explicitRank(b, explicit)); r != 0 {
+		return r
+	}
+	for _, k := range keys {
+		var r int
+		switch k {
+		case "sort":
+			r = cmp.Compare(a.Sort, b.Sort)
+		case "name":
+			r = cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.SubGroup, b.SubGroup))
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.SubGroup, b.SubGroup))
+}
+
+// explicitRank is the position of g in the explicit group order, or
+// len(explicit) for groups not listed. Both "Parent" and the full
+// "Parent/Child" path are accepted.
+func explicitRank(g GroupData, explicit []string) int {
+	full := g.Name
+	if g.SubGroup != "" {
+		full += "/" + g.SubGroup
+	}
+	for i, name := range explicit {
+		if name == full || name == g.Name {
+			return i
+		}
+	}
+	return len(explicit)
+}
+
+func sortItems(items []ServiceItem, keys []string) {
+	slices.SortStableFunc(items, func(a, b ServiceItem) int { return compareItems(a, b, keys) })
+}
+
+// sortGroups orders groups by policy. It is stable, so groups that still tie
+// keep their input order, which buildTemplateData builds in group key order.
+func sortGroups(groups []GroupData, policy config.OrderPolicy) {
+	slices.SortStableFunc(groups, func(a, b GroupData) int {
+		return compareGroups(a, b, policy.GroupOrder, policy.GroupKeys)
+	})
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestCompareItems(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	tests := []struct {
+		name string
+		a, b ServiceItem
+		keys []string
+		want int
+	}{
+		{name: "sort first", a: ServiceItem{Sort: 1, Name: "b"}, b: ServiceItem{Sort: 2, Name: "a"}, keys: []string{"sort", "name"}, want: -1},
+		{name: "name breaks sort tie", a: ServiceItem{Name: "b"}, b: ServiceItem{Name: "a"}, keys: []string{"sort", "name"}, want: 1},
+		{name: "sort-key breaks tie", a: ServiceItem{SortKey: "a", Name: "z"}, b: ServiceItem{SortKey: "b", Name: "a"}, keys: []string{"sort-key", "name"}, want: -1},
+		{name: "created newest first", a: ServiceItem{Created: older}, b: ServiceItem{Created: newer}, keys: []string{"created"}, want: 1},
+		{name: "url tie-break", a: ServiceItem{URL: "https://a"}, b: ServiceItem{URL: "https://b"}, keys: []string{"name"}, want: -1},
+		{name: "namespace tie-break", a: ServiceItem{Namespace: "b"}, b: ServiceItem{Namespace: "a"}, keys: []string{"name"}, want: 1},
+		{name: "route tie-break", a: ServiceItem{Namespace: "a", Route: "x"}, b: ServiceItem{Namespace: "a", Route: "y"}, keys: []string{"name"}, want: -1},
+		{name: "pinned first", a: ServiceItem{Sort: sortFirst}, b: ServiceItem{Sort: -100}, keys: []string{"sort"}, want: -1},
+		{name: "pinned last", a: ServiceItem{Sort: sortLast}, b: ServiceItem{Sort: 100}, keys: []string{"sort"}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareItems(tt.a, tt.b, tt.keys); got != tt.want {
+				t.Errorf("compareItems = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     GroupData
+		explicit []string
+		keys     []string
+		want     int
+	}{
+		{name: "explicit wins", a: GroupData{Name: "Z"}, b: GroupData{Name: "A"}, explicit: []string{"Z"}, keys: []string{"name"}, want: -1},
+		{name: "explicit parent matches sub-group", a: GroupData{Name: "Z", SubGroup: "x"}, b: GroupData{Name: "A"}, explicit: []string{"Z"}, keys: []string{"name"}, want: -1},
+		{name: "sort key", a: GroupData{Name: "A", Sort: 2}, b: GroupData{Name: "B", Sort: 1}, keys: []string{"sort"}, want: 1},
+		{name: "equal sort falls back to name", a: GroupData{Name: "B", Sort: 1}, b: GroupData{Name: "A", Sort: 1}, keys: []string{"sort"}, want: 1},
+		{name: "equal sort falls back to sub-group", a: GroupData{Name: "A", SubGroup: "a"}, b: GroupData{Name: "A", SubGroup: "b"}, keys: []string{"sort"}, want: -1},
+		{name: "identical paths tie", a: GroupData{Name: "A", SubGroup: "x"}, b: GroupData{Name: "A", SubGroup: "x"}, keys: []string{"sort", "name"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareGroups(tt.a, tt.b, tt.explicit, tt.keys); got != tt.want {
+				t.Errorf("compareGroups = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOverrideGroupOrder checks that items pulled into one group from several
+// namespaces with equal sort values come out in the same order whatever order
+// the routes are listed in, down to the namespace and route name tie-breaks.
+func TestOverrideGroupOrder(t *testing.T) {
+	p := config.AnnotationPrefix
+	core := []runtime.Object{testNamespace("alpha", nil), testNamespace("mid", nil), testNamespace("zeta", nil)}
+	route := func(ns, name, title, sort, host string) *gwv1.HTTPRoute {
+		ann := map[string]string{p + "/group": "Shared", p + "/name": title, p + "/sort": sort, p + "/subtitle": ns + "/" + name}
+		return testRoute(ns, name, ann, host)
+	}
+	routes := []runtime.Object{
+		route("zeta", "dash", "Dashboard", "1", "dash.zeta.example.com"),
+		route("alpha", "dash", "Dashboard", "1", "dash.alpha.example.com"),
+		route("mid", "apps", "Apps", "1", "apps.example.com"),
+		route("zeta", "mirror", "Mirror", "1", "mirror.example.com"),
+		route("mid", "mirror-b", "Mirror", "1", "mirror.example.com"),
+		route("mid", "mirror-a", "Mirror", "1", "mirror.example.com"),
+		route("alpha", "late", "Later", "2", "late.example.com"),
+	}
+	want := []string{"mid/apps", "alpha/dash", "zeta/dash", "mid/mirror-a", "mid/mirror-b", "zeta/mirror", "alpha/late"}
+
+	cfg := testConfig()
+	cfg.Order = config.OrderPolicy{GroupKeys: []string{"name"}, ItemKeys: []string{"sort", "name"}}
+	for i := 0; i < 2; i++ {
+		c, _ := newTestController(cfg, core, routes...)
+		configs, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		var got []string
+		for _, line := range strings.Split(configs[cfg.ConfigMapName], "\n") {
+			if _, sub, ok := strings.Cut(line, "subtitle: "); ok && strings.Contains(sub, "/") {
+				got = append(got, strings.Trim(sub, `"`))
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("listing %d: item order = %v, want %v", i, got, want)
+		}
+		slices.Reverse(routes)
+	}
+}
+
+// TestOrderingGolden renders a realistic cluster and compares the config with
+// testdata/ordering.golden; run with -update to rewrite it. It renders several
+// times, since map iteration order would otherwise show up as flaky output.
+func TestOrderingGolden(t *testing.T) {
+	p := config.AnnotationPrefix
+	core := []runtime.Object{
+		testNamespace("media", map[string]string{p + "/group-icon": "fas fa-film", p + "/group-sort": "1"}),
+		testNamespace("monitoring", map[string]string{p + "/group-sort": "1"}),
+		testNamespace("home", map[string]string{p + "/group": "Home Automation"}),
+		testNamespace("tools", nil),
+	}
+	routes := []runtime.Object{
+		testRoute("media", "sonarr", map[string]string{p + "/sort": "2"}, "sonarr.example.com"),
+		testRoute("media", "radarr", map[string]string{p + "/sort": "2"}, "radarr.example.com"),
+		testRoute("media", "jellyfin", map[string]string{p + "/sort": "first"}, "jellyfin.example.com"),
+		testRoute("media", "plex", map[string]string{p + "/group": "Media / TV"}, "plex.example.com"),
+		testRoute("tools", "tvheadend", map[string]string{p + "/group": "Media/TV"}, "tv.example.com"),
+		testRoute("monitoring", "grafana", map[string]string{p + "/subtitle": "Metrics"}, "grafana.example.com"),
+		testRoute("monitoring", "uptime", map[string]string{p + "/sort": "last"}, "uptime.example.com"),
+		testRoute("monitoring", "alertmanager", nil, "alerts.example.com"),
+		testRoute("home", "hass", nil, "hass.example.com"),
+		testRoute("tools", "it-tools", map[string]string{p + "/group": "Shared"}, "it-tools.example.com"),
+		testRoute("home", "zigbee", map[string]string{p + "/group": "Shared", p + "/hidden": "true"}, "zigbee.example.com"),
+		testRoute("tools", "cyberchef", map[string]string{p + "/group": "Shared"}, "cyberchef.example.com"),
+	}
+
+	cfg := testConfig()
+	cfg.NoHeader = true
+	cfg.Order = config.OrderPolicy{GroupKeys: []string{"sort"}, ItemKeys: []string{"sort", "name"}}
+
+	var first string
+	for i := 0; i < 5; i++ {
+		c, _ := newTestController(cfg, core, routes...)
+		configs, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		got := configs[cfg.ConfigMapName]
+		if i == 0 {
+			first = got
+		} else if got != first {
+			t.Fatalf("render %d differs from the first:\n%s\n---\n%s", i, got, first)
+		}
+	}
+
+	golden := filepath.Join("testdata", "ordering.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(first), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != string(want) {
+		t.Errorf("rendered config differs from %s:\n%s", golden, lineDiff(string(want), first))
+	}
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ownerReference resolves the object that should own the output ConfigMap:
+// the Deployment behind the controller's pod when there is one, otherwise the
+// pod's controlling owner, otherwise the pod itself. The result is cached for
+// the lifetime of the process. It returns nil when owner references are
+// disabled or cannot be set (e.g. the ConfigMap lives in another namespace or
+// cluster).
+func (c *Controller) ownerReference(ctx context.Context) (*metav1.OwnerReference, error) {
+	if !c.cfg.SetOwnerReference {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	cached := c.owner
+	c.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	ns, podName := c.cfg.SelfNamespace, c.cfg.PodName
+	if ns == "" || podName == "" {
+		syncLog().Warn("cannot set owner reference: POD_NAMESPACE/POD_NAME not set")
+		return nil, nil
+	}
+	if c.cfg.RemoteOutput() {
+		syncLog().Warn("cannot set owner reference on a ConfigMap in another cluster")
+		return nil, nil
+	}
+	if ns != c.cfg.ConfigMapNamespace {
+		syncLog().Warn("cannot set owner reference across namespaces", "pod_namespace", ns, "configmap_namespace", c.cfg.ConfigMapNamespace)
+		return nil, nil
+	}
+
+	podCtx, cancelPod := c.apiContext(ctx)
+	pod, err := c.clients.Core.CoreV1().Pods(ns).Get(podCtx, podName, metav1.GetOptions{})
+	cancelPod()
+	if err != nil {
+		return nil, fmt.Errorf("get pod %s/%s: %w", ns, podName, err)
+	}
+
+	ref := &metav1.OwnerReference{APIVersion: "v1", Kind: "Pod", Name: pod.Name, UID: pod.UID}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		ref = owner.DeepCopy()
+		if owner.Kind == "ReplicaSet" {
+			rsCtx, cancelRS := c.apiContext(ctx)
+			rs, err := c.clients.Core.AppsV1().ReplicaSets(ns).Get(rsCtx, owner.Name, metav1.GetOptions{})
+			cancelRS()
+			if err != nil {
+				return nil, fmt.Errorf("get replicaset %s/%s: %w", ns, owner.Name, err)
+			}
+			if dep := metav1.GetControllerOf(rs); dep != nil {
+				ref = dep.DeepCopy()
+			}
+		}
+	}
+	// Only reference the owner; homer-sync does not control GC behaviour.
+	ref.Controller = nil
+	ref.BlockOwnerDeletion = nil
+
+	c.mu.Lock()
+	c.owner = ref
+	c.mu.Unlock()
+	syncLog().Debug("resolved owner reference", "kind", ref.Kind, "name", ref.Name)
+	return ref, nil
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// permission is one access right homer-sync needs, checked with a
+// SelfSubjectAccessReview against the cluster client that uses it.
+type permission struct {
+	client kubernetes.Interface
+	attrs  authorizationv1.ResourceAttributes
+}
+
+// requiredPermissions lists the access rights the current configuration
+// needs: listing namespaces and the enabled sources on the source cluster,
+// reading and writing each output object on the output cluster, and whatever
+// the optional features in use touch (status ConfigMap, events, shard
+// pruning, owner references, Secret references).
+func (c *Controller) requiredPermissions() []permission {
+	list := func(group, resource string) permission {
+		return permission{client: c.clients.Core, attrs: authorizationv1.ResourceAttributes{
+			Verb: "list", Group: group, Resource: resource,
+		}}
+	}
+
+	perms := []permission{list("", "namespaces")}
+	if slices.Contains(c.cfg.Sources, "httproute") {
+		perms = append(perms, list("gateway.networking.k8s.io", "httproutes"))
+	}
+	if slices.Contains(c.cfg.RouteKinds, "grpcroute") {
+		perms = append(perms, list("gateway.networking.k8s.io", "grpcroutes"))
+	}
+	if slices.Contains(c.cfg.RouteKinds, "tcproute") {
+		perms = append(perms, list("gateway.networking.k8s.io", "tcproutes"))
+	}
+	if slices.Contains(c.cfg.Sources, "ingress") {
+		perms = append(perms, list("networking.k8s.io", "ingresses"))
+	}
+	if slices.Contains(c.cfg.Sources, "openshift-route") {
+		perms = append(perms, list("route.openshift.io", "routes"))
+	}
+	if c.cfg.BackendAnnotations {
+		perms = append(perms, list("", "services"))
+	}
+	if c.cfg.ResolveSecrets {
+		for _, verb := range []string{"get", "watch"} {
+			perms = append(perms, permission{client: c.clients.Core, attrs: authorizationv1.ResourceAttributes{
+				Verb: verb, Resource: "secrets",
+			}})
+		}
+	}
+
+	if c.cfg.DryRun {
+		return perms
+	}
+	if target := c.cfg.StatusConfigMap; target.Name != "" {
+		perms = append(perms, objectPermissions(c.output(), "configmaps", target.Namespace, target.Name, "get", "create", "update")...)
+	}
+	if c.cfg.EmitEvents {
+		perms = append(perms, objectPermissions(c.output(), "events", c.cfg.ConfigMapNamespace, "", "create", "patch")...)
+	}
+	if c.cfg.OutputFile != "" {
+		return perms
+	}
+
+	resource := "configmaps"
+	if c.cfg.OutputKind == "secret" {
+		resource = "secrets"
+	}
+	names := []string{c.cfg.ConfigMapName}
+	for _, m := range c.cfg.OutputMap {
+		names = append(names, m.Name)
+	}
+	for _, name := range names {
+		perms = append(perms, objectPermissions(c.output(), resource, c.cfg.ConfigMapNamespace, name, "get", "create", "patch")...)
+	}
+	if c.cfg.AutoShard {
+		// Shards are numbered, so check deleting any object of the kind.
+		perms = append(perms, objectPermissions(c.output(), resource, c.cfg.ConfigMapNamespace, "", "delete")...)
+	}
+	if c.cfg.SetOwnerReference && c.cfg.SelfNamespace != "" {
+		perms = append(perms,
+			permission{client: c.clients.Core, attrs: authorizationv1.ResourceAttributes{
+				Verb: "get", Resource: "pods", Namespace: c.cfg.SelfNamespace, Name: c.cfg.PodName,
+			}},
+			permission{client: c.clients.Core, attrs: authorizationv1.ResourceAttributes{
+				Verb: "get", Group: "apps", Resource: "replicasets", Namespace: c.cfg.SelfNamespace,
+			}},
+		)
+	}
+	return perms
+}
+
+// objectPermissions returns one permission per verb on the named core
+// object. Creates are authorised before the object has a name, so they are
+// checked without one.
+func objectPermissions(client kubernetes.Interface, resource, ns, name string, verbs ...string) []permission {
+	perms := make([]permission, 0, len(verbs))
+	for _, verb := range verbs {
+		n := name
+		if verb == "create" {
+			n = ""
+		}
+		perms = append(perms, permission{client: client, attrs: authorizationv1.ResourceAttributes{
+			Verb: verb, Resource: resource, Namespace: ns, Name: n,
+		}})
+	}
+	return perms
+}
+
+// Preflight checks that homer-sync can do its job with the current
+// configuration: every required permission is granted and every CRD-backed
+// resource is served. It writes a pass/fail table to w and returns an error
+// when anything is missing.
+func (c *Controller) Preflight(ctx context.Context, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tNAMESPACE\tNAME\tRESULT")
+
+	failed := 0
+	for _, p := range c.requiredPermissions() {
+		a := p.attrs
+		result := "pass"
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &a},
+		}
+		apiCtx, cancel := c.apiContext(ctx)
+		resp, err := p.client.AuthorizationV1().SelfSubjectAccessReviews().Create(apiCtx, review, metav1.CreateOptions{})
+		cancel()
+		switch {
+		case err != nil:
+			result = "error: " + err.Error()
+			failed++
+		case !resp.Status.Allowed:
+			result = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\n", a.Verb, qualifiedResource(a), stringOr(a.Namespace, "*"), stringOr(a.Name, "*"), result)
+	}
+
+	result := "pass"
+	if err := c.preflight(ctx); err != nil {
+		result = "FAIL: " + err.Error()
+		failed++
+	}
+	fmt.Fprintf(tw, "served APIs\t-\t-\t%s\n", result)
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d preflight check(s) failed", failed)
+	}
+	return nil
+}
+
+// qualifiedResource formats a resource as resource.group, e.g.
+// httproutes.gateway.networking.k8s.io; core resources have no group.
+func qualifiedResource(a authorizationv1.ResourceAttributes) string {
+	if a.Group == "" {
+		return a.Resource
+	}
+	return a.Resource + "." + a.Group
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// permissionStrings formats perms as "verb resource namespace/name".
+func permissionStrings(perms []permission) []string {
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		a := p.attrs
+		out = append(out, a.Verb+" "+qualifiedResource(a)+" "+a.Namespace+"/"+a.Name)
+	}
+	return out
+}
+
+func TestRequiredPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*config.Config)
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"list namespaces /", "list httproutes.gateway.networking.k8s.io /",
+				"get configmaps default/homer-config", "create configmaps default/", "patch configmaps default/homer-config",
+			},
+			notWant: []string{"delete configmaps default/", "create events default/", "get pods self/homer-sync-abc"},
+		},
+		{
+			name:  "auto-shard",
+			setup: func(c *config.Config) { c.AutoShard = true },
+			want:  []string{"delete configmaps default/"},
+		},
+		{
+			name:  "auto-shard secret",
+			setup: func(c *config.Config) { c.AutoShard, c.OutputKind = true, "secret" },
+			want:  []string{"delete secrets default/", "patch secrets default/homer-config"},
+		},
+		{
+			name:  "events",
+			setup: func(c *config.Config) { c.EmitEvents = true },
+			want:  []string{"create events default/", "patch events default/"},
+		},
+		{
+			name:  "status configmap",
+			setup: func(c *config.Config) { c.StatusConfigMap = config.ObjectRef{Namespace: "ops", Name: "status"} },
+			want:  []string{"get configmaps ops/status", "create configmaps ops/", "update configmaps ops/status"},
+		},
+		{
+			name: "owner reference",
+			setup: func(c *config.Config) {
+				c.SetOwnerReference, c.SelfNamespace, c.PodName = true, "self", "homer-sync-abc"
+			},
+			want: []string{"get pods self/homer-sync-abc", "get replicasets.apps self/"},
+		},
+		{
+			name:  "secret references",
+			setup: func(c *config.Config) { c.ResolveSecrets = true },
+			want:  []string{"get secrets /", "watch secrets /"},
+		},
+		{
+			name:    "output file skips output writes",
+			setup:   func(c *config.Config) { c.OutputFile, c.AutoShard, c.EmitEvents = "/tmp/x", true, true },
+			want:    []string{"create events default/"},
+			notWant: []string{"patch configmaps default/homer-config", "delete configmaps default/"},
+		},
+		{
+			name:    "dry run writes nothing",
+			setup:   func(c *config.Config) { c.DryRun, c.EmitEvents = true, true },
+			notWant: []string{"create events default/", "patch configmaps default/homer-config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+			c, _ := newTestController(cfg, nil)
+			got := permissionStrings(c.requiredPermissions())
+			for _, w := range tt.want {
+				if !slices.Contains(got, w) {
+					t.Errorf("missing %q in %v", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if slices.Contains(got, w) {
+					t.Errorf("unexpected %q in %v", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPreflightReportsDenied(t *testing.T) {
+	cfg := testConfig()
+	cfg.AutoShard = true
+	c, cs := newTestController(cfg, nil)
+	cs.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb != "delete"
+		return true, review, nil
+	})
+
+	var out bytes.Buffer
+	err := c.Preflight(context.Background(), &out)
+	if err == nil || !strings.Contains(err.Error(), "1 preflight check(s) failed") {
+		t.Fatalf("Preflight error = %v, want one failed check", err)
+	}
+	if !strings.Contains(out.String(), "delete configmaps") || !strings.Contains(out.String(), "FAIL") {
+		t.Errorf("table does not report the denied delete:\n%s", out.String())
+	}
+}
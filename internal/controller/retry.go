@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// retryBaseDelay is the wait before the first retry of a transient API error;
+// it doubles with each further attempt.
+const retryBaseDelay = 200 * time.Millisecond
+
+// isRetryable reports whether err is a transient API failure worth retrying
+// within the same scan.
+func isRetryable(err error) bool {
+	return apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// listWithRetry runs list, each attempt bounded by --api-timeout, retrying
+// transient errors up to --api-retries times with a short exponential backoff
+// (or the server's Retry-After hint). Other errors are returned immediately.
+func listWithRetry[T any](ctx context.Context, c *Controller, what string, list func(context.Context) (T, error)) (T, error) {
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		callCtx, cancel := c.apiContext(ctx)
+		result, err := list(callCtx)
+		cancel()
+		if err == nil || attempt >= c.cfg.APIRetries || !isRetryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+
+		wait := delay
+		if secs, ok := apierrors.SuggestsClientDelay(err); ok {
+			wait = time.Duration(secs) * time.Second
+		}
+		ctrlLog().Warn("transient API error; retrying", "list", what, "error", err, "attempt", attempt+1, "retries", c.cfg.APIRetries, "delay", wait)
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestListWithRetry(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("etcd leader changed")
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", nil)
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		retries  int
+		attempts int
+		wantErr  bool
+	}{
+		{name: "succeeds after transient errors", failures: 2, err: unavailable, retries: 3, attempts: 3},
+		{name: "gives up after the retries", failures: 2, err: unavailable, retries: 1, attempts: 2, wantErr: true},
+		{name: "no retries", failures: 1, err: unavailable, retries: 0, attempts: 1, wantErr: true},
+		{name: "permanent error", failures: 1, err: forbidden, retries: 3, attempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.APIRetries = tt.retries
+			c, cs := newTestController(cfg, []runtime.Object{testNamespace("media", nil)})
+			attempts := 0
+			cs.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+				attempts++
+				if attempts <= tt.failures {
+					return true, nil, tt.err
+				}
+				return false, nil, nil
+			})
+
+			list, err := listWithRetry(context.Background(), c, "namespaces", func(ctx context.Context) (*corev1.NamespaceList, error) {
+				return cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listWithRetry error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.attempts)
+			}
+			if !tt.wantErr && len(list.Items) != 1 {
+				t.Errorf("listed %d namespaces, want 1", len(list.Items))
+			}
+		})
+	}
+}
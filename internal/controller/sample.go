@@ -0,0 +1,54 @@
+package controller
+
+import "time"
+
+// RenderSample renders the template source src against fixed sample data, so a
+// custom template can be checked without a cluster. It returns the rendered
+// config or the parse, execution or YAML validation error.
+func RenderSample(src string) (string, error) {
+	return renderConfig(sampleTemplateData(), singleTemplate(src), false, false)
+}
+
+// sampleTemplateData returns a small but representative TemplateData: two
+// groups (one of them a sub-group), a message, a link and items exercising
+// the optional fields.
+func sampleTemplateData() TemplateData {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	media := []ServiceItem{
+		{
+			Namespace: "media", Route: "jellyfin", Name: "Jellyfin", Subtitle: "Movies and TV",
+			URL: "https://jellyfin.example.com", Icon: "jellyfin", Group: "Media", GroupIcon: "fas fa-film",
+			Tag: "prod", TagStyle: "is-success", Class: "highlight", Keywords: []string{"movies", "tv"}, Target: "_blank", Created: created,
+		},
+		{
+			Namespace: "media", Route: "sonarr", Name: "Sonarr",
+			URL: "https://sonarr.example.com", Logo: "https://cdn.example.com/sonarr.png", Group: "Media", GroupIcon: "fas fa-film",
+			Sort: 1, Created: created,
+		},
+	}
+	monitoring := []ServiceItem{
+		{
+			Namespace: "monitoring", Route: "grafana", Name: "Grafana", Subtitle: "Metrics",
+			URL: "https://grafana.example.com", Icon: "grafana", Group: "Infra/Monitoring", GroupIcon: "fas fa-chart-line",
+			Type: "Ping", Endpoint: "https://grafana.example.com/api/health", Headers: HealthHeaders{"Authorization": "Bearer example"}, Created: created,
+		},
+	}
+	return TemplateData{
+		Title:    "Home Dashboard",
+		Subtitle: "Sample data",
+		Columns:  3,
+		Message: &MessageData{
+			Style:   "is-warning",
+			Title:   "Maintenance",
+			Icon:    "fas fa-exclamation-triangle",
+			Content: "Storage upgrade tonight.",
+		},
+		Links: []LinkData{{Name: "Docs", URL: "https://docs.example.com", Icon: "fas fa-book", Target: "_blank"}},
+		Groups: []GroupData{
+			{Name: "Media", Icon: "fas fa-film", Columns: 3, Count: len(media), Items: media},
+			{Name: "Infra", SubGroup: "Monitoring", Icon: "fas fa-chart-line", Columns: 2, Count: len(monitoring), Items: monitoring},
+		},
+		TotalServices: len(media) + len(monitoring),
+		TotalGroups:   2,
+	}
+}
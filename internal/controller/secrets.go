@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// secretWatchRetry is how long a failed Secret watch waits before retrying.
+const secretWatchRetry = 5 * time.Second
+
+// SecretKeyRef points at one key of a Secret.
+type SecretKeyRef struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// id returns the "namespace/name" of the referenced Secret.
+func (r SecretKeyRef) id() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// apiKeyRef parses the home.mirceanton.com/apikey-secret annotation,
+// "<secret>/<key>" in the route's own namespace. It is ignored without
+// --resolve-secrets, so route authors cannot publish Secret values unless the
+// operator opts in.
+func (c *Controller) apiKeyRef(ns, name string, ann map[string]string) (SecretKeyRef, bool) {
+	raw := strings.TrimSpace(ann[config.AnnotationPrefix+"/apikey-secret"])
+	if raw == "" {
+		return SecretKeyRef{}, false
+	}
+	if !c.cfg.ResolveSecrets {
+		ctrlLog().Debug("ignoring apikey-secret annotation without --resolve-secrets", "namespace", ns, "name", name)
+		return SecretKeyRef{}, false
+	}
+	secret, key, ok := strings.Cut(raw, "/")
+	if !ok || secret == "" || key == "" {
+		ctrlLog().Warn("ignoring invalid apikey-secret annotation: expected <secret>/<key>", "namespace", ns, "name", name, "value", raw)
+		return SecretKeyRef{}, false
+	}
+	return SecretKeyRef{Namespace: ns, Name: secret, Key: key}, true
+}
+
+// resolveSecrets reads every Secret the items reference, once per scan, and
+// fills in their API keys. A missing Secret or key leaves the key empty with a
+// warning rather than failing the scan. The resource versions read are
+// recorded so watchSecrets can tell a rotation from a replayed event.
+func (c *Controller) resolveSecrets(ctx context.Context, items []ServiceItem) {
+	secrets := make(map[string]*corev1.Secret)
+	versions := make(map[string]string)
+	for i := range items {
+		ref := items[i].APIKeyRef
+		if ref.Name == "" {
+			continue
+		}
+		secret, seen := secrets[ref.id()]
+		if !seen {
+			getCtx, cancel := c.apiContext(ctx)
+			s, err := c.clients.Core.CoreV1().Secrets(ref.Namespace).Get(getCtx, ref.Name, metav1.GetOptions{})
+			cancel()
+			if err != nil {
+				// Recorded without a version, so creating it triggers a
+				// re-render.
+				ctrlLog().Warn("cannot read referenced secret", "namespace", ref.Namespace, "name", ref.Name, "error", err)
+				s = nil
+				versions[ref.id()] = ""
+			} else {
+				versions[ref.id()] = s.ResourceVersion
+			}
+			secrets[ref.id()] = s
+			secret = s
+		}
+		if secret == nil {
+			continue
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			ctrlLog().Warn("referenced secret has no such key", "namespace", ref.Namespace, "name", ref.Name, "key", ref.Key)
+			continue
+		}
+		items[i].APIKey = string(value)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, rv := range versions {
+		if last, ok := c.secretVersions[id]; ok && last != rv {
+			ctrlLog().Info("referenced secret changed; re-syncing", "secret", id)
+		}
+	}
+	c.secretVersions = versions
+}
+
+// secretChanged reports whether a watch event for the Secret id differs from
+// what the last scan read: a referenced Secret with a new resource version, or
+// one that was deleted.
+func (c *Controller) secretChanged(id string, ev watch.Event) bool {
+	secret, ok := ev.Object.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	last, referenced := c.secretVersions[id]
+	c.mu.Unlock()
+	if !referenced {
+		return false
+	}
+	return ev.Type == watch.Deleted || secret.ResourceVersion != last
+}
+
+// updateSecretWatches makes sure every namespace holding a referenced Secret
+// is watched, and stops the watches of namespaces no longer needed. It runs
+// after each daemon scan, from the Run goroutine only.
+func (c *Controller) updateSecretWatches(ctx context.Context, reload chan<- struct{}) {
+	if !c.cfg.ResolveSecrets {
+		return
+	}
+	want := make(map[string]bool)
+	c.mu.Lock()
+	for id := range c.secretVersions {
+		ns, _, _ := strings.Cut(id, "/")
+		want[ns] = true
+	}
+	c.mu.Unlock()
+
+	if c.secretWatches == nil {
+		c.secretWatches = make(map[string]context.CancelFunc)
+	}
+	for ns, cancel := range c.secretWatches {
+		if !want[ns] {
+			cancel()
+			delete(c.secretWatches, ns)
+		}
+	}
+	for ns := range want {
+		if _, ok := c.secretWatches[ns]; ok {
+			continue
+		}
+		wctx, cancel := context.WithCancel(ctx)
+		c.secretWatches[ns] = cancel
+		go c.watchSecrets(wctx, ns, reload)
+	}
+}
+
+// watchSecrets watches the Secrets of ns and signals reload when one an item
+// references changes, so a rotated credential reaches the dashboard without
+// waiting for the next scan. A dropped watch is re-established until ctx is
+// cancelled.
+func (c *Controller) watchSecrets(ctx context.Context, ns string, reload chan<- struct{}) {
+	for {
+		w, err := c.clients.Core.CoreV1().Secrets(ns).Watch(ctx, metav1.ListOptions{})
+		if err != nil {
+			ctrlLog().Warn("cannot watch referenced secrets; rotations apply on the next scan", "namespace", ns, "error", err)
+		} else {
+			c.drainSecretWatch(ctx, ns, w, reload)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(secretWatchRetry):
+		}
+	}
+}
+
+// drainSecretWatch forwards the events of w until it closes or ctx is done.
+func (c *Controller) drainSecretWatch(ctx context.Context, ns string, w watch.Interface, reload chan<- struct{}) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			secret, isSecret := ev.Object.(*corev1.Secret)
+			if !isSecret || !c.secretChanged(ns+"/"+secret.Name, ev) {
+				continue
+			}
+			ctrlLog().Info("referenced secret changed; re-rendering", "namespace", ns, "name", secret.Name)
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
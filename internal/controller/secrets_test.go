@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+func testSecret(ns, name, rv string, data map[string]string) *corev1.Secret {
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, ResourceVersion: rv}, Data: map[string][]byte{}}
+	for k, v := range data {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+func TestAPIKeyRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve bool
+		value   string
+		want    SecretKeyRef
+		wantOK  bool
+	}{
+		{name: "valid", resolve: true, value: "creds/token", want: SecretKeyRef{Namespace: "media", Name: "creds", Key: "token"}, wantOK: true},
+		{name: "disabled", resolve: false, value: "creds/token"},
+		{name: "unset", resolve: true, value: ""},
+		{name: "no key", resolve: true, value: "creds"},
+		{name: "empty key", resolve: true, value: "creds/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{cfg: &config.Config{ResolveSecrets: tt.resolve}}
+			ann := map[string]string{config.AnnotationPrefix + "/apikey-secret": tt.value}
+			got, ok := c.apiKeyRef("media", "app", ann)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("apiKeyRef(%q) = %+v, %v; want %+v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	cfg := testConfig()
+	cfg.ResolveSecrets = true
+	c, _ := newTestController(cfg, []runtime.Object{testSecret("media", "creds", "7", map[string]string{"token": "s3cret"})})
+
+	items := []ServiceItem{
+		{Name: "a", APIKeyRef: SecretKeyRef{Namespace: "media", Name: "creds", Key: "token"}},
+		{Name: "b", APIKeyRef: SecretKeyRef{Namespace: "media", Name: "creds", Key: "missing"}},
+		{Name: "c", APIKeyRef: SecretKeyRef{Namespace: "media", Name: "absent", Key: "token"}},
+		{Name: "d"},
+	}
+	c.resolveSecrets(context.Background(), items)
+
+	for i, want := range []string{"s3cret", "", "", ""} {
+		if items[i].APIKey != want {
+			t.Errorf("item %s: APIKey = %q, want %q", items[i].Name, items[i].APIKey, want)
+		}
+	}
+	wantVersions := map[string]string{"media/creds": "7", "media/absent": ""}
+	if fmt.Sprint(c.secretVersions) != fmt.Sprint(wantVersions) {
+		t.Errorf("secretVersions = %v, want %v", c.secretVersions, wantVersions)
+	}
+}
+
+func TestSecretChangeTriggersReconcile(t *testing.T) {
+	cfg := testConfig()
+	cfg.ResolveSecrets = true
+	cfg.Daemon = true
+	cfg.ScanInterval = 3600
+
+	ann := map[string]string{config.AnnotationPrefix + "/apikey-secret": "creds/token"}
+	c, cs := newTestController(cfg,
+		[]runtime.Object{testNamespace("media", nil), testSecret("media", "creds", "1", map[string]string{"token": "old"})},
+		testRoute("media", "app", ann, "app.example.com"),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	waitFor(t, func() bool { return configHas(cs, cfg, `apikey: "old"`) })
+
+	// The watch starts after the first scan; keep rotating until one lands.
+	rv := 2
+	waitFor(t, func() bool {
+		secret := testSecret("media", "creds", fmt.Sprint(rv), map[string]string{"token": "new"})
+		rv++
+		if _, err := cs.CoreV1().Secrets("media").Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("update secret: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		return configHas(cs, cfg, `apikey: "new"`)
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
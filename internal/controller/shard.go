@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// shardSize is the largest config --auto-shard puts in one object, leaving
+// room below objectSizeLimit for metadata and other keys.
+const shardSize = objectSizeLimit - 64*1024
+
+// shardName returns the name of shard i of the output named name. Shard 0 is
+// the output object itself, which Homer mounts as config.yml, so it never
+// goes stale while the config is sharded.
+func shardName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, i)
+}
+
+// renderOutput renders the config of one output target. With --auto-shard, a
+// config larger than shardSize is split by group into several configs, to be
+// written to numbered objects. render is passed the index of the shard being
+// rendered: shard 0 is a complete Homer config, the others additional pages.
+func (c *Controller) renderOutput(out outputTarget, render func(map[string][]ServiceItem, int) (string, error)) ([]string, error) {
+	whole, err := render(out.groups, 0)
+	if err != nil || !c.cfg.AutoShard || len(whole) <= shardSize {
+		return []string{whole}, err
+	}
+
+	// Fill shards greedily, group by group in name order, starting a new
+	// shard whenever the next group would push the current one over.
+	names := make([]string, 0, len(out.groups))
+	for g := range out.groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+
+	var shards []string
+	current := make(map[string][]ServiceItem)
+	last := ""
+	for _, g := range names {
+		current[g] = out.groups[g]
+		rendered, err := render(current, len(shards))
+		if err != nil {
+			return nil, err
+		}
+		if len(rendered) <= shardSize {
+			last = rendered
+			continue
+		}
+		if len(current) == 1 {
+			return nil, fmt.Errorf("group %q alone renders to %d bytes, over the %d byte shard size", g, len(rendered), shardSize)
+		}
+		shards = append(shards, last)
+		current = map[string][]ServiceItem{g: out.groups[g]}
+		if last, err = render(current, len(shards)); err != nil {
+			return nil, err
+		}
+		if len(last) > shardSize {
+			return nil, fmt.Errorf("group %q alone renders to %d bytes, over the %d byte shard size", g, len(last), shardSize)
+		}
+	}
+	shards = append(shards, last)
+	syncLog().Info("config too large for one object; sharding", "name", out.name, "bytes", len(whole), "shards", len(shards))
+	return shards, nil
+}
+
+// pruneShards deletes shards of the output named name from index keep on,
+// left over from a run that needed more of them. keep is at least 1: shard 0
+// is the output object itself. Shards are numbered without gaps, so it stops
+// at the first one that does not exist.
+func (c *Controller) pruneShards(ctx context.Context, name string, keep int) error {
+	if c.cfg.OutputFile != "" || c.cfg.DryRun {
+		return nil
+	}
+	ns := c.cfg.ConfigMapNamespace
+	for i := keep; ; i++ {
+		shard := shardName(name, i)
+		delCtx, cancel := c.apiContext(ctx)
+		var err error
+		if c.cfg.OutputKind == "secret" {
+			err = c.output().CoreV1().Secrets(ns).Delete(delCtx, shard, metav1.DeleteOptions{})
+		} else {
+			err = c.output().CoreV1().ConfigMaps(ns).Delete(delCtx, shard, metav1.DeleteOptions{})
+		}
+		cancel()
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("delete stale shard %s/%s: %w", ns, shard, err)
+		}
+		syncLog().Info("deleted stale shard", "namespace", ns, "name", shard)
+	}
+}
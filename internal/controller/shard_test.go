@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// sizedRender stands in for the template: every group renders to its items'
+// names, so a test controls the config size through them.
+func sizedRender(groups map[string][]ServiceItem, _ int) (string, error) {
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, g := range names {
+		for _, it := range groups[g] {
+			b.WriteString(it.Name)
+		}
+	}
+	return b.String(), nil
+}
+
+func sizedGroup(tag byte, size int) []ServiceItem {
+	return []ServiceItem{{Name: strings.Repeat(string(tag), size)}}
+}
+
+func TestRenderOutputShards(t *testing.T) {
+	third := shardSize / 3
+	groups := map[string][]ServiceItem{
+		"a": sizedGroup('a', third),
+		"b": sizedGroup('b', third),
+		"c": sizedGroup('c', third),
+		"d": sizedGroup('d', third),
+	}
+
+	c := &Controller{cfg: testConfig()}
+	shards, err := c.renderOutput(outputTarget{name: "homer-config", groups: groups}, sizedRender)
+	if err != nil || len(shards) != 1 {
+		t.Fatalf("without --auto-shard: %d configs, %v; want the whole config", len(shards), err)
+	}
+
+	c.cfg.AutoShard = true
+	shards, err = c.renderOutput(outputTarget{name: "homer-config", groups: groups}, sizedRender)
+	if err != nil {
+		t.Fatalf("renderOutput: %v", err)
+	}
+	var firsts []byte
+	for _, s := range shards {
+		if len(s) > shardSize {
+			t.Errorf("shard of %d bytes exceeds %d", len(s), shardSize)
+		}
+		firsts = append(firsts, s[0])
+	}
+	if string(firsts) != "ad" {
+		t.Errorf("shards start with groups %q, want %q", firsts, "ad")
+	}
+
+	groups["huge"] = sizedGroup('h', shardSize+1)
+	if _, err := c.renderOutput(outputTarget{name: "homer-config", groups: groups}, sizedRender); err == nil {
+		t.Error("renderOutput accepted a group larger than a shard")
+	}
+}
+
+func TestPruneShards(t *testing.T) {
+	cfg := testConfig()
+	var core []runtime.Object
+	for i := 0; i < 4; i++ {
+		core = append(core, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: cfg.ConfigMapNamespace, Name: shardName(cfg.ConfigMapName, i)}})
+	}
+	c, cs := newTestController(cfg, core)
+
+	if err := c.pruneShards(context.Background(), cfg.ConfigMapName, 2); err != nil {
+		t.Fatalf("pruneShards: %v", err)
+	}
+	list, _ := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).List(context.Background(), metav1.ListOptions{})
+	var names []string
+	for _, cm := range list.Items {
+		names = append(names, cm.Name)
+	}
+	sort.Strings(names)
+	if want := []string{"homer-config", "homer-config-1"}; !slices.Equal(names, want) {
+		t.Errorf("remaining shards = %v, want %v", names, want)
+	}
+}
+
+func TestShardPageFields(t *testing.T) {
+	c := &Controller{cfg: testConfig()}
+	c.cfg.SummaryGroup = config.SummaryGroup{Mode: "all", Name: "Everything"}
+	groups := map[string][]ServiceItem{
+		"media": {{Name: "Plex", URL: "https://plex.example.com", Group: "media"}},
+	}
+	src := singleTemplate(builtinTemplate("v1"))
+
+	first, err := c.buildTemplateData(groups, nil, nil, nil, src, 0)
+	if err != nil {
+		t.Fatalf("render shard 0: %v", err)
+	}
+	for _, want := range []string{`title: "Home"`, "links:", `name: "Everything"`, `name: "Plex"`} {
+		if !strings.Contains(first, want) {
+			t.Errorf("shard 0 lacks %q:\n%s", want, first)
+		}
+	}
+
+	// Later shards are Homer pages inheriting config.yml's page-level
+	// fields; repeating them, or the summary group, would override it.
+	later, err := c.buildTemplateData(groups, nil, nil, nil, src, 1)
+	if err != nil {
+		t.Fatalf("render shard 1: %v", err)
+	}
+	for _, unwanted := range []string{"\ntitle:", "\nlinks:", "\ncolumns:", "Everything"} {
+		if strings.Contains(later, unwanted) {
+			t.Errorf("shard 1 contains %q:\n%s", unwanted, later)
+		}
+	}
+	if !strings.Contains(later, `name: "Plex"`) {
+		t.Errorf("shard 1 lacks its services:\n%s", later)
+	}
+}
+
+func TestAutoShardWritesOutputObject(t *testing.T) {
+	cfg := testConfig()
+	cfg.AutoShard = true
+	subtitle := config.AnnotationPrefix + "/subtitle"
+	var core []runtime.Object
+	var routes []runtime.Object
+	for _, ns := range []string{"a", "b", "c", "d"} {
+		core = append(core, testNamespace(ns, nil))
+		routes = append(routes, testRoute(ns, "app", map[string]string{subtitle: strings.Repeat(ns, shardSize/4)}, ns+".example.com"))
+	}
+	// A stale unsharded config must not survive as the mounted config.yml.
+	core = append(core, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cfg.ConfigMapNamespace, Name: cfg.ConfigMapName},
+		Data:       map[string]string{cfg.ConfigMapKey: "stale"},
+	})
+	c, cs := newTestController(cfg, core, routes...)
+
+	if _, err := c.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce: %v", err)
+	}
+	base := outputConfig(t, cs, cfg)
+	if !strings.Contains(base, `title: "Home"`) || !strings.Contains(base, "a.example.com") {
+		t.Errorf("output object does not hold shard 0:\n%.200s", base)
+	}
+	cm, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), shardName(cfg.ConfigMapName, 1), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get shard 1: %v", err)
+	}
+	if page := cm.Data[cfg.ConfigMapKey]; !strings.Contains(page, "d.example.com") || strings.Contains(page, "\ntitle:") {
+		t.Errorf("shard 1 is not a services-only page:\n%.200s", page)
+	}
+	if _, err := cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), shardName(cfg.ConfigMapName, 2), metav1.GetOptions{}); err == nil {
+		t.Error("wrote more shards than needed")
+	}
+}
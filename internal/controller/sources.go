@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// fetchRoutes lists every configured source kind and returns the results in
+// the shared route-map shape consumed by shouldInclude/extractItem.
+func (c *Controller) fetchRoutes(ctx context.Context) ([]map[string]interface{}, error) {
+	var routes []map[string]interface{}
+
+	if slices.Contains(c.cfg.Sources, "httproute") {
+		httpRoutes, err := c.fetchHTTPRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetch httproutes: %w", err)
+		}
+		ctrlLog().Debug("found httproutes", "count", len(httpRoutes))
+		routes = append(routes, httpRoutes...)
+	}
+
+	if slices.Contains(c.cfg.Sources, "ingress") {
+		ingresses, err := c.fetchIngresses(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetch ingresses: %w", err)
+		}
+		ctrlLog().Debug("found ingresses", "count", len(ingresses))
+		routes = append(routes, ingresses...)
+	}
+
+	if slices.Contains(c.cfg.Sources, "openshift-route") {
+		osRoutes, err := c.fetchOpenShiftRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetch openshift routes: %w", err)
+		}
+		ctrlLog().Debug("found openshift routes", "count", len(osRoutes))
+		routes = append(routes, osRoutes...)
+	}
+
+	if slices.Contains(c.cfg.RouteKinds, "grpcroute") {
+		grpcRoutes, err := c.fetchGRPCRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetch grpcroutes: %w", err)
+		}
+		ctrlLog().Debug("found grpcroutes", "count", len(grpcRoutes))
+		routes = append(routes, grpcRoutes...)
+	}
+
+	if slices.Contains(c.cfg.RouteKinds, "tcproute") {
+		tcpRoutes, err := c.fetchTCPRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fetch tcproutes: %w", err)
+		}
+		ctrlLog().Debug("found tcproutes", "count", len(tcpRoutes))
+		routes = append(routes, tcpRoutes...)
+	}
+
+	return routes, nil
+}
+
+// routeListOptions applies the configured label selector so route filtering
+// happens server-side. Annotation-based filtering still runs afterwards.
+func (c *Controller) routeListOptions() metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: c.cfg.RouteLabelSelector}
+}
+
+func (c *Controller) fetchGRPCRoutes(ctx context.Context) ([]map[string]interface{}, error) {
+	list, err := listWithRetry(ctx, c, "grpcroutes", func(ctx context.Context) (*gwv1.GRPCRouteList, error) {
+		return c.clients.Gateway.GatewayV1().GRPCRoutes("").List(ctx, c.routeListOptions())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list grpcroutes: %w", c.missingAPI(err, "GRPCRoute"))
+	}
+
+	routes := make([]map[string]interface{}, 0, len(list.Items))
+	for _, r := range list.Items {
+		ann := r.Annotations
+		if ann == nil {
+			ann = make(map[string]string)
+		}
+		routes = append(routes, map[string]interface{}{
+			"kind":              "GRPCRoute",
+			"namespace":         r.Namespace,
+			"name":              r.Name,
+			"annotations":       ann,
+			"labels":            r.Labels,
+			"parentRefs":        parentRefMaps(r.Spec.ParentRefs, r.Namespace),
+			"parentStatuses":    acceptedConditions(r.Status.RouteStatus),
+			"hostnames":         hostnameStrings(r.Spec.Hostnames),
+			"backendRefs":       backendServiceRefs(grpcBackendRefs(r.Spec.Rules), r.Namespace),
+			"creationTimestamp": r.CreationTimestamp.Time,
+		})
+	}
+	return routes, nil
+}
+
+// fetchTCPRoutes lists v1alpha2 TCPRoutes. They carry no hostnames, so their
+// link must come from the home.mirceanton.com/url annotation.
+func (c *Controller) fetchTCPRoutes(ctx context.Context) ([]map[string]interface{}, error) {
+	list, err := listWithRetry(ctx, c, "tcproutes", func(ctx context.Context) (*gwv1alpha2.TCPRouteList, error) {
+		return c.clients.Gateway.GatewayV1alpha2().TCPRoutes("").List(ctx, c.routeListOptions())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list tcproutes: %w", c.missingAPI(err, "TCPRoute"))
+	}
+
+	routes := make([]map[string]interface{}, 0, len(list.Items))
+	for _, r := range list.Items {
+		ann := r.Annotations
+		if ann == nil {
+			ann = make(map[string]string)
+		}
+		routes = append(routes, map[string]interface{}{
+			"kind":              "TCPRoute",
+			"namespace":         r.Namespace,
+			"name":              r.Name,
+			"annotations":       ann,
+			"labels":            r.Labels,
+			"parentRefs":        parentRefMaps(r.Spec.ParentRefs, r.Namespace),
+			"parentStatuses":    acceptedConditions(r.Status.RouteStatus),
+			"hostnames":         []string{},
+			"creationTimestamp": r.CreationTimestamp.Time,
+		})
+	}
+	return routes, nil
+}
+
+// parentRefMaps converts Gateway API parentRefs into the route-map shape. A
+// parentRef without a namespace refers to the route's own namespace.
+func parentRefMaps(refs []gwv1.ParentReference, routeNamespace string) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(refs))
+	for _, pr := range refs {
+		ns := routeNamespace
+		if pr.Namespace != nil {
+			ns = string(*pr.Namespace)
+		}
+		ref := map[string]interface{}{
+			"name":      string(pr.Name),
+			"namespace": ns,
+		}
+		if pr.SectionName != nil {
+			ref["sectionName"] = string(*pr.SectionName)
+		}
+		out = append(out, ref)
+	}
+	return out
+}
+
+// acceptedConditions extracts each parent's Accepted condition from a route
+// status, as maps with the parent name, condition status and reason. Parents
+// that have not reported one yet get an "Unknown" status.
+func acceptedConditions(status gwv1.RouteStatus) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(status.Parents))
+	for _, p := range status.Parents {
+		entry := map[string]interface{}{
+			"parent": string(p.ParentRef.Name),
+			"status": string(metav1.ConditionUnknown),
+			"reason": "",
+		}
+		if cond := meta.FindStatusCondition(p.Conditions, string(gwv1.RouteConditionAccepted)); cond != nil {
+			entry["status"] = string(cond.Status)
+			entry["reason"] = cond.Reason
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func hostnameStrings(hostnames []gwv1.Hostname) []string {
+	out := make([]string, 0, len(hostnames))
+	for _, h := range hostnames {
+		out = append(out, string(h))
+	}
+	return out
+}
+
+// fetchIngresses lists networking.k8s.io/v1 Ingresses. Hostnames come from
+// spec.rules[].host followed by any extra spec.tls[].hosts, and the ingress
+// class stands in for the parentRef name so --gateway-names can filter by it.
+func (c *Controller) fetchIngresses(ctx context.Context) ([]map[string]interface{}, error) {
+	list, err := listWithRetry(ctx, c, "ingresses", func(ctx context.Context) (*networkingv1.IngressList, error) {
+		return c.clients.Core.NetworkingV1().Ingresses("").List(ctx, c.routeListOptions())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list ingresses: %w", err)
+	}
+
+	routes := make([]map[string]interface{}, 0, len(list.Items))
+	for _, ing := range list.Items {
+		parentRefs := make([]map[string]interface{}, 0, 1)
+		if ing.Spec.IngressClassName != nil && *ing.Spec.IngressClassName != "" {
+			parentRefs = append(parentRefs, map[string]interface{}{
+				"name": *ing.Spec.IngressClassName,
+			})
+		}
+
+		var hostnames []string
+		addHost := func(h string) {
+			if h != "" && !slices.Contains(hostnames, h) {
+				hostnames = append(hostnames, h)
+			}
+		}
+		for _, rule := range ing.Spec.Rules {
+			addHost(rule.Host)
+		}
+		for _, tls := range ing.Spec.TLS {
+			for _, h := range tls.Hosts {
+				addHost(h)
+			}
+		}
+
+		ann := ing.Annotations
+		if ann == nil {
+			ann = make(map[string]string)
+		}
+
+		routes = append(routes, map[string]interface{}{
+			"kind":              "Ingress",
+			"namespace":         ing.Namespace,
+			"name":              ing.Name,
+			"annotations":       ann,
+			"labels":            ing.Labels,
+			"parentRefs":        parentRefs,
+			"hostnames":         hostnames,
+			"creationTimestamp": ing.CreationTimestamp.Time,
+		})
+	}
+	return routes, nil
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reportStatus writes the outcome of a scan to the --status-configmap, if one
+// is configured, whether or not the dashboard itself changed. Failures are
+// logged rather than returned so status reporting never fails a scan.
+func (c *Controller) reportStatus(ctx context.Context, sum ScanSummary, scanErr error) {
+	target := c.cfg.StatusConfigMap
+	if target.Name == "" || c.cfg.DryRun {
+		return
+	}
+
+	c.mu.Lock()
+	lastSync := c.lastSuccessfulSync
+	c.mu.Unlock()
+
+	data := map[string]string{
+		"lastScanTime": time.Now().UTC().Format(time.RFC3339),
+		"lastSyncTime": "",
+		"result":       "success",
+		"services":     strconv.Itoa(sum.Included),
+		"hidden":       strconv.Itoa(sum.Hidden),
+		"skipped":      strconv.Itoa(sum.Skipped),
+		"groups":       strconv.Itoa(sum.Groups),
+		"lastError":    "",
+	}
+	if !lastSync.IsZero() {
+		data["lastSyncTime"] = lastSync.UTC().Format(time.RFC3339)
+	}
+	if scanErr != nil {
+		data["result"] = "error"
+		data["lastError"] = scanErr.Error()
+	}
+
+	cms := c.output().CoreV1().ConfigMaps(target.Namespace)
+	getCtx, cancelGet := c.apiContext(ctx)
+	existing, err := cms.Get(getCtx, target.Name, metav1.GetOptions{})
+	cancelGet()
+	switch {
+	case errors.IsNotFound(err):
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: target.Name, Namespace: target.Namespace},
+			Data:       data,
+		}
+		createCtx, cancelCreate := c.apiContext(ctx)
+		_, err = cms.Create(createCtx, cm, metav1.CreateOptions{})
+		cancelCreate()
+	case err == nil:
+		existing.Data = data
+		updateCtx, cancelUpdate := c.apiContext(ctx)
+		_, err = cms.Update(updateCtx, existing, metav1.UpdateOptions{})
+		cancelUpdate()
+	}
+	if err != nil {
+		syncLog().Warn("failed to write status configmap", "namespace", target.Namespace, "name", target.Name, "error", err)
+		return
+	}
+	syncLog().Debug("wrote status configmap", "namespace", target.Namespace, "name", target.Name)
+}
@@ -4,52 +4,251 @@ import (
 	_ "embed"
 	"bytes"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"text/template"
+	"time"
+
+	"sigs.k8s.io/yaml"
 )
 
 //go:embed default.tmpl
 var defaultTemplate string
 
+//go:embed default-v2.tmpl
+var defaultTemplateV2 string
+
+// builtinTemplate returns the embedded template for the --homer-schema
+// version: "v2" targets newer Homer releases, anything else the classic shape.
+func builtinTemplate(schema string) string {
+	if schema == "v2" {
+		return defaultTemplateV2
+	}
+	return defaultTemplate
+}
+
 // TemplateData is the context passed to the Homer config template.
 type TemplateData struct {
 	Title    string
 	Subtitle string
+	Theme    string
+	Colors   map[string]interface{}
 	Columns  int
+	Message  *MessageData
+	Links    []LinkData
 	Groups   []GroupData
+	// TotalServices and TotalGroups count the visible items and groups,
+	// excluding the summary group.
+	TotalServices int
+	TotalGroups   int
+	// Shard is the index of the --auto-shard shard rendered, 0 when the
+	// config fits in one object. Later shards are mounted as additional
+	// Homer pages, which inherit the page-level fields (title, links,
+	// message, ...) of config.yml, so the built-in templates render only
+	// their services.
+	Shard int
+}
+
+// LinkData is one entry of Homer's top-level links bar.
+type LinkData struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Icon   string `json:"icon"`
+	Target string `json:"target,omitempty"`
+}
+
+// MessageData is Homer's optional top-of-page message block.
+type MessageData struct {
+	Style   string
+	Title   string
+	Icon    string
+	Content string
 }
 
 // GroupData represents one Homer service group with its sorted items.
+// Groups named with a "Parent/Child" path carry the parent in Name and the
+// child in SubGroup so custom templates can render a hierarchy. ColumnItems is
+// set only when an item pins itself to a column and holds Items split into
+// Columns explicit columns; Items is then reordered row by row to match.
+// Count is the number of visible items.
 type GroupData struct {
-	Name  string
-	Icon  string
-	Items []ServiceItem
+	Name        string
+	SubGroup    string
+	Icon        string
+	Columns     int
+	Sort        int
+	Count       int
+	Items       []ServiceItem
+	ColumnItems [][]ServiceItem
+}
+
+// templateFuncs is the small, sprig-compatible helper set available to
+// templates. Argument order follows sprig so pipelines read naturally, e.g.
+// {{ .Subtitle | default "n/a" }} or {{ .Name | replace " " "-" | lower }}.
+//
+//	lower s              lowercase
+//	upper s              uppercase
+//	title s              capitalise each word
+//	default def val      val, or def when val is empty
+//	trimSuffix suffix s  strip suffix from s
+//	hasPrefix prefix s   whether s starts with prefix
+//	replace old new s    replace every old with new
+//	urlencode s          query-escape s
+//	join sep list        join list with sep
+//	toYaml v             v marshalled as YAML, without the trailing newline
+//	indent n s           indent every line of s by n spaces
+var templateFuncs = template.FuncMap{
+	"toYaml": func(v interface{}) (string, error) {
+		out, err := yaml.Marshal(v)
+		return strings.TrimSuffix(string(out), "\n"), err
+	},
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"title":      titleCase,
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"urlencode":  url.QueryEscape,
+	"join":       func(sep string, list []string) string { return strings.Join(list, sep) },
+	"default": func(def, val interface{}) interface{} {
+		if val == nil || reflect.ValueOf(val).IsZero() {
+			return def
+		}
+		return val
+	},
+}
+
+// headerPrefix starts the generated-by comment prepended to rendered configs.
+const headerPrefix = "# Generated by homer-sync at "
+
+// generatedHeader returns the generated-by comment line for time t.
+func generatedHeader(t time.Time) string {
+	return headerPrefix + t.UTC().Format(time.RFC3339) + " — do not edit manually\n"
+}
+
+// stripHeader removes a leading generated-by comment line from s, if present.
+func stripHeader(s string) string {
+	if !strings.HasPrefix(s, headerPrefix) {
+		return s
+	}
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return ""
+}
+
+// templateFile is one named template of a templateSource.
+type templateFile struct {
+	Name string
+	Text string
+}
+
+// templateSource is the template text a scan renders with: a single template,
+// or a --template-dir set whose files can include each other by name. Entry
+// names the template that is executed.
+type templateSource struct {
+	Entry string
+	Files []templateFile
+}
+
+// singleTemplate wraps a lone template source under the "homer" entry name.
+func singleTemplate(text string) templateSource {
+	return templateSource{Entry: "homer", Files: []templateFile{{Name: "homer", Text: text}}}
+}
+
+// equal reports whether s and o hold the same templates.
+func (s templateSource) equal(o templateSource) bool {
+	return s.Entry == o.Entry && slices.Equal(s.Files, o.Files)
 }
 
-// renderConfig executes the Homer config template against data and returns the
-// rendered YAML string.  When templatePath is non-empty and the file exists it
-// is used as the template; otherwise the built-in default is used.
-func renderConfig(data TemplateData, templatePath string) (string, error) {
-	var src string
+// loadTemplateDir reads every *.tmpl file in dir, in name order, naming each
+// template after its file name without the extension, so header.tmpl is
+// included with {{ template "header" . }}. Templates declared with
+// {{ define }} in any file are available as well.
+func loadTemplateDir(dir, entry string) (templateSource, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return templateSource{}, fmt.Errorf("list templates in %q: %w", dir, err)
+	}
+	if len(paths) == 0 {
+		return templateSource{}, fmt.Errorf("no *.tmpl files in %q", dir)
+	}
 
-	if templatePath != "" {
-		raw, err := os.ReadFile(templatePath)
+	src := templateSource{Entry: entry}
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
 		if err != nil {
-			return "", fmt.Errorf("read custom template %q: %w", templatePath, err)
+			return templateSource{}, fmt.Errorf("read template %q: %w", p, err)
 		}
-		src = string(raw)
-	} else {
-		src = defaultTemplate
+		src.Files = append(src.Files, templateFile{Name: strings.TrimSuffix(filepath.Base(p), ".tmpl"), Text: string(raw)})
 	}
+	return src, nil
+}
 
-	tmpl, err := template.New("homer").Parse(src)
-	if err != nil {
-		return "", fmt.Errorf("parse template: %w", err)
+// renderConfig executes the entry template of src against data and returns
+// the rendered YAML string, re-serialised with sorted keys when canonical is
+// set and prefixed with a generated-by comment when header is set.
+func renderConfig(data TemplateData, src templateSource, header, canonical bool) (string, error) {
+	tmpl := template.New(src.Entry).Funcs(templateFuncs)
+	for _, f := range src.Files {
+		if _, err := tmpl.New(f.Name).Parse(f.Text); err != nil {
+			return "", fmt.Errorf("parse template: %w", err)
+		}
+	}
+	if tmpl.Lookup(src.Entry) == nil {
+		return "", fmt.Errorf("parse template: entry template %q is not defined", src.Entry)
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := tmpl.ExecuteTemplate(&buf, src.Entry, data); err != nil {
 		return "", fmt.Errorf("execute template: %w", err)
 	}
-	return buf.String(), nil
+
+	if err := validateYAML(buf.Bytes()); err != nil {
+		return "", err
+	}
+	out := buf.String()
+	if canonical {
+		var err error
+		if out, err = canonicalYAML(buf.Bytes()); err != nil {
+			return "", err
+		}
+	}
+	if header {
+		return generatedHeader(time.Now()) + out, nil
+	}
+	return out, nil
+}
+
+// canonicalYAML round-trips rendered through JSON and back, so mapping keys
+// come out sorted and formatting is uniform whatever the template emitted.
+// Comments are dropped.
+func canonicalYAML(rendered []byte) (string, error) {
+	j, err := yaml.YAMLToJSON(rendered)
+	if err != nil {
+		return "", fmt.Errorf("canonicalize rendered config: %w", err)
+	}
+	out, err := yaml.JSONToYAML(j)
+	if err != nil {
+		return "", fmt.Errorf("canonicalize rendered config: %w", err)
+	}
+	return string(out), nil
+}
+
+// validateYAML rejects rendered output that does not parse as a YAML mapping,
+// so a broken template never reaches the ConfigMap.
+func validateYAML(rendered []byte) error {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(rendered, &doc); err != nil {
+		return fmt.Errorf("rendered config is not valid YAML: %w", err)
+	}
+	return nil
 }
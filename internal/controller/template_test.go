@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBrokenTemplateWritesNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{
+		{name: "bad syntax", tmpl: "services:\n{{ range .Groups }}\n", wantErr: "parse template"},
+		{name: "unknown function", tmpl: "title: {{ .Title | shout }}\n", wantErr: "parse template"},
+		{name: "invalid yaml", tmpl: "title: {{ .Title }}\n  services: [\n", wantErr: "YAML"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.TemplatePath = filepath.Join(t.TempDir(), "homer.tmpl")
+			if err := os.WriteFile(cfg.TemplatePath, []byte(tt.tmpl), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			c, cs := newTestController(cfg, []runtime.Object{testNamespace("media", nil)},
+				testRoute("media", "plex", nil, "plex.example.com"))
+
+			sum, err := c.runOnce(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("runOnce error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+			if sum.RenderErrors != 1 {
+				t.Errorf("render errors = %d, want 1", sum.RenderErrors)
+			}
+			_, err = cs.CoreV1().ConfigMaps(cfg.ConfigMapNamespace).Get(context.Background(), cfg.ConfigMapName, metav1.GetOptions{})
+			if !errors.IsNotFound(err) {
+				t.Errorf("output configmap written despite the broken template (get: %v)", err)
+			}
+		})
+	}
+}
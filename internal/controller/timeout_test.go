@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+	gwv1client "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/typed/apis/v1"
+)
+
+// slowGateway is a Gateway API client whose HTTPRoute lists hang until their
+// context is done, like a stalled API server.
+type slowGateway struct{ gwclient.Interface }
+
+func (g slowGateway) GatewayV1() gwv1client.GatewayV1Interface {
+	return slowGatewayV1{g.Interface.GatewayV1()}
+}
+
+type slowGatewayV1 struct{ gwv1client.GatewayV1Interface }
+
+func (g slowGatewayV1) HTTPRoutes(ns string) gwv1client.HTTPRouteInterface {
+	return slowHTTPRoutes{g.GatewayV1Interface.HTTPRoutes(ns)}
+}
+
+type slowHTTPRoutes struct{ gwv1client.HTTPRouteInterface }
+
+func (slowHTTPRoutes) List(ctx context.Context, _ metav1.ListOptions) (*gwv1.HTTPRouteList, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAPITimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.APITimeout = 1
+	c, cs := newTestController(cfg, []runtime.Object{testNamespace("media", nil)})
+	c.clients.Gateway = slowGateway{c.clients.Gateway}
+
+	start := time.Now()
+	_, err := c.runOnce(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("runOnce = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("runOnce took %s with a 1s --api-timeout", elapsed)
+	}
+	if configHas(cs, cfg, "") {
+		t.Error("output ConfigMap written after a failed scan")
+	}
+}
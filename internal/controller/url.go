@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+// routeURL derives the link for one of the route's hostnames, honouring the
+// url and path annotations with the precedence explicit url > hostname+path >
+// hostname, then applies --url-rewrite and normalizes it unless
+// --no-url-normalize is set. An empty result means the route has no usable
+// link.
+func (c *Controller) routeURL(route map[string]interface{}, hostname string) string {
+	u := c.rewriteURL(c.rawRouteURL(route, hostname))
+	if c.cfg.NoURLNormalize {
+		return u
+	}
+	return normalizeURL(u)
+}
+
+// rewriteURL applies the --url-rewrite rules to u in order. By default only
+// the first matching rule applies; with --url-rewrite-mode=all each rule
+// applies to the previous one's result.
+func (c *Controller) rewriteURL(u string) string {
+	if u == "" {
+		return u
+	}
+	for _, r := range c.cfg.URLRewrites {
+		if !r.Pattern.MatchString(u) {
+			continue
+		}
+		u = r.Pattern.ReplaceAllString(u, r.Replacement)
+		if !c.cfg.URLRewriteAll {
+			break
+		}
+	}
+	return u
+}
+
+// rawRouteURL is routeURL without rewriting or normalization.
+func (c *Controller) rawRouteURL(route map[string]interface{}, hostname string) string {
+	ann := routeAnnotations(route)
+	hostURL := ""
+	if hostname != "" {
+		hostURL = routeScheme(route, hostname, c.cfg.DefaultScheme) + "://" + hostname
+	}
+
+	explicit := ann[config.AnnotationPrefix+"/url"]
+	if p := strings.TrimSpace(ann[config.AnnotationPrefix+"/path"]); p != "" && hostURL != "" && strings.TrimSpace(explicit) == "" {
+		withPath, err := joinURLPath(hostURL, p)
+		if err != nil {
+			ctrlLog().Warn("ignoring path annotation", "namespace", route["namespace"], "name", route["name"], "error", err)
+			return hostURL
+		}
+		return withPath
+	}
+
+	u, err := resolveURL(explicit, c.cfg.URLBase, hostURL)
+	if err != nil {
+		ctrlLog().Warn("ignoring url annotation", "namespace", route["namespace"], "name", route["name"], "error", err)
+		return hostURL
+	}
+	return u
+}
+
+// normalizeURL returns a canonical form of raw, so equivalent links render and
+// dedupe the same: scheme and host are lowercased, default ports (80 for
+// http, 443 for https) dropped, a bare "/" path removed and repeated trailing
+// slashes collapsed to one. Values that do not parse as absolute URLs are
+// returned unchanged.
+func normalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	// Leave escaped paths alone; rewriting Path would drop their encoding.
+	if u.RawPath == "" {
+		if u.Path == "/" {
+			u.Path = ""
+		}
+		for strings.HasSuffix(u.Path, "//") {
+			u.Path = u.Path[:len(u.Path)-1]
+		}
+	}
+	return u.String()
+}
+
+// routeScheme picks http or https for a route's hostname URL. An explicit
+// home.mirceanton.com/scheme annotation wins, then a scheme the source already
+// knows (OpenShift TLS termination); otherwise a parentRef section name
+// naming a plain-HTTP listener (e.g. "http", "web") selects http, while one
+// mentioning https/tls selects https. Without any of these, --default-scheme
+// decides: "auto" uses http for hosts unlikely to have a certificate (see
+// plainHTTPHost) and https for the rest.
+func routeScheme(route map[string]interface{}, hostname, defaultScheme string) string {
+	ann := routeAnnotations(route)
+	switch s := strings.ToLower(strings.TrimSpace(ann[config.AnnotationPrefix+"/scheme"])); s {
+	case "http", "https":
+		return s
+	case "":
+	default:
+		ctrlLog().Warn("ignoring invalid scheme annotation", "namespace", route["namespace"], "name", route["name"], "value", s)
+	}
+	if s, ok := route["scheme"].(string); ok && s != "" {
+		return s
+	}
+
+	refs, _ := route["parentRefs"].([]map[string]interface{})
+	sawPlain := false
+	for _, ref := range refs {
+		section, _ := ref["sectionName"].(string)
+		section = strings.ToLower(section)
+		switch {
+		case section == "":
+		case strings.Contains(section, "https") || strings.Contains(section, "tls"):
+			return "https"
+		case strings.Contains(section, "http") || section == "web":
+			sawPlain = true
+		}
+	}
+	if sawPlain {
+		return "http"
+	}
+	if defaultScheme == "http" || defaultScheme == "https" {
+		return defaultScheme
+	}
+	if plainHTTPHost(hostname) {
+		return "http"
+	}
+	return "https"
+}
+
+// plainHTTPHost reports whether hostname is an IP literal, localhost, a
+// single-label name or under .local — hosts that rarely serve a valid
+// certificate.
+func plainHTTPHost(hostname string) bool {
+	h := strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if host, _, err := net.SplitHostPort(h); err == nil {
+		h = host
+	}
+	h = strings.Trim(h, "[]")
+	if net.ParseIP(h) != nil {
+		return true
+	}
+	return h == "localhost" || !strings.Contains(h, ".") || strings.HasSuffix(h, ".local")
+}
+
+// expandMultiURL turns item into one item per route hostname when the route
+// sets home.mirceanton.com/multi-url. The hostname preferredHostname picks
+// comes first and keeps the plain name; with "true" each other item is
+// suffixed with its hostname, while a comma-separated list labels the
+// hostnames in spec order, e.g. "internal,external" yields "App (internal)"
+// and "App (external)". Hostnames leading to a URL already emitted (e.g. all of
+// them, under a url annotation) are dropped. Without the annotation, or when
+// only one URL remains, item is returned as-is.
+func (c *Controller) expandMultiURL(route map[string]interface{}, item ServiceItem) []ServiceItem {
+	ann := routeAnnotations(route)
+	mode := strings.TrimSpace(ann[config.AnnotationPrefix+"/multi-url"])
+	hostnames, _ := route["hostnames"].([]string)
+	if mode == "" || strings.EqualFold(mode, "false") || len(hostnames) < 2 {
+		return []ServiceItem{item}
+	}
+
+	var labels []string
+	if !strings.EqualFold(mode, "true") {
+		labels = strings.Split(mode, ",")
+	}
+
+	primary := c.preferredHostname(hostnames)
+	order := make([]int, 0, len(hostnames))
+	for i, h := range hostnames {
+		if h == primary {
+			order = append([]int{i}, order...)
+		} else {
+			order = append(order, i)
+		}
+	}
+
+	items := make([]ServiceItem, 0, len(hostnames))
+	seen := make(map[string]bool, len(hostnames))
+	for n, i := range order {
+		h := hostnames[i]
+		it := item
+		it.URL = c.routeURL(route, h)
+		if seen[it.URL] {
+			continue
+		}
+		seen[it.URL] = true
+		if item.ProbeURL == item.URL {
+			it.ProbeURL = it.URL
+		}
+		if item.Endpoint == item.URL {
+			it.Endpoint = it.URL
+		}
+		switch {
+		case i < len(labels) && strings.TrimSpace(labels[i]) != "":
+			it.Name = fmt.Sprintf("%s (%s)", item.Name, strings.TrimSpace(labels[i]))
+		case n > 0:
+			it.Name = fmt.Sprintf("%s (%s)", item.Name, h)
+		}
+		items = append(items, it)
+	}
+	if len(items) < 2 {
+		return []ServiceItem{item}
+	}
+	return items
+}
+
+// joinURLPath appends path to base with exactly one slash between them and
+// checks that the result is still an absolute URL.
+func joinURLPath(base, path string) (string, error) {
+	joined := strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+	u, err := url.Parse(joined)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", joined, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("url %q is not absolute", joined)
+	}
+	return joined, nil
+}
+
+// resolveURL derives a service link from the route's url annotation and its
+// hostname-based URL:
+//
+//   - no annotation: the hostname URL is used as-is
+//   - absolute annotation (scheme://host/...): used verbatim
+//   - annotation starting with "/": resolved against base, or against the
+//     hostname URL when no base is configured
+//
+// Any other annotation value is rejected so the caller can fall back.
+func resolveURL(annotation, base, hostURL string) (string, error) {
+	annotation = strings.TrimSpace(annotation)
+	if annotation == "" {
+		return hostURL, nil
+	}
+
+	if strings.HasPrefix(annotation, "/") {
+		root := base
+		if root == "" {
+			root = hostURL
+		}
+		if root == "" {
+			return "", fmt.Errorf("relative url %q needs a url base or a route hostname", annotation)
+		}
+		joined := strings.TrimRight(root, "/") + annotation
+		if _, err := url.Parse(joined); err != nil {
+			return "", fmt.Errorf("invalid url %q: %w", joined, err)
+		}
+		return joined, nil
+	}
+
+	u, err := url.Parse(annotation)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", annotation, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("url %q is neither absolute nor rooted at /", annotation)
+	}
+	return annotation, nil
+}
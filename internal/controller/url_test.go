@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+func TestExpandMultiURL(t *testing.T) {
+	p := config.AnnotationPrefix
+	tests := []struct {
+		name      string
+		ann       map[string]string
+		hostnames []string
+		prefer    []string
+		shortest  bool
+		want      []string // "name url"
+	}{
+		{
+			name:      "disabled",
+			hostnames: []string{"app.lan", "app.example.com"},
+			want:      []string{"App https://app.lan"},
+		},
+		{
+			name:      "spec order without preference",
+			ann:       map[string]string{p + "/multi-url": "true"},
+			hostnames: []string{"app.lan", "app.example.com"},
+			want:      []string{"App https://app.lan", "App (app.example.com) https://app.example.com"},
+		},
+		{
+			name:      "preferred suffix first",
+			ann:       map[string]string{p + "/multi-url": "true"},
+			hostnames: []string{"app.lan", "app.example.com"},
+			prefer:    []string{".example.com"},
+			want:      []string{"App https://app.example.com", "App (app.lan) https://app.lan"},
+		},
+		{
+			name:      "shortest first",
+			ann:       map[string]string{p + "/multi-url": "true"},
+			hostnames: []string{"app.internal.example.com", "app.lan"},
+			shortest:  true,
+			want:      []string{"App https://app.lan", "App (app.internal.example.com) https://app.internal.example.com"},
+		},
+		{
+			name:      "labels follow hostnames",
+			ann:       map[string]string{p + "/multi-url": "internal,external"},
+			hostnames: []string{"app.lan", "app.example.com"},
+			prefer:    []string{".example.com"},
+			want:      []string{"App (external) https://app.example.com", "App (internal) https://app.lan"},
+		},
+		{
+			name:      "url annotation dedupes",
+			ann:       map[string]string{p + "/multi-url": "true", p + "/url": "https://app.example.com/ui"},
+			hostnames: []string{"app.lan", "app.example.com"},
+			want:      []string{"App https://app.example.com/ui"},
+		},
+		{
+			name:      "duplicate hostnames dedupe",
+			ann:       map[string]string{p + "/multi-url": "true"},
+			hostnames: []string{"app.lan", "app.lan", "app.example.com"},
+			want:      []string{"App https://app.lan", "App (app.example.com) https://app.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PreferSuffixes, cfg.PreferShortest = tt.prefer, tt.shortest
+			c := New(nil, cfg)
+			route := map[string]interface{}{"namespace": "media", "name": "app", "annotations": tt.ann, "hostnames": tt.hostnames}
+			item := ServiceItem{Name: "App", URL: c.routeURL(route, c.preferredHostname(tt.hostnames))}
+			var got []string
+			for _, it := range c.expandMultiURL(route, item) {
+				got = append(got, it.Name+" "+it.URL)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandMultiURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		base       string
+		hostURL    string
+		want       string
+		wantErr    bool
+	}{
+		{name: "no annotation", hostURL: "https://app.example.com", want: "https://app.example.com"},
+		{name: "absolute", annotation: " http://other.example.com/x ", hostURL: "https://app.example.com", want: "http://other.example.com/x"},
+		{name: "rooted against hostname", annotation: "/admin", hostURL: "https://app.example.com/", want: "https://app.example.com/admin"},
+		{name: "rooted against base", annotation: "/admin", base: "https://proxy.example.com/", hostURL: "https://app.example.com", want: "https://proxy.example.com/admin"},
+		{name: "rooted without base or hostname", annotation: "/admin", wantErr: true},
+		{name: "host-less", annotation: "foo", hostURL: "https://app.example.com", wantErr: true},
+		{name: "scheme-less host", annotation: "app.example.com/x", hostURL: "https://app.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveURL(tt.annotation, tt.base, tt.hostURL)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("resolveURL(%q, %q, %q) = %q, %v; want %q, error %v", tt.annotation, tt.base, tt.hostURL, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJoinURLPath(t *testing.T) {
+	tests := []struct {
+		base, path string
+		want       string
+		wantErr    bool
+	}{
+		{base: "https://app.example.com", path: "admin", want: "https://app.example.com/admin"},
+		{base: "https://app.example.com/", path: "/admin/", want: "https://app.example.com/admin/"},
+		{base: "https://app.example.com//", path: "//admin", want: "https://app.example.com/admin"},
+		{base: "app.example.com", path: "admin", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := joinURLPath(tt.base, tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("joinURLPath(%q, %q) = %q, %v; want %q, error %v", tt.base, tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRawRouteURLPath(t *testing.T) {
+	p := config.AnnotationPrefix
+	tests := []struct {
+		name string
+		ann  map[string]string
+		want string
+	}{
+		{name: "path joined to hostname", ann: map[string]string{p + "/path": "/admin"}, want: "https://app.example.com/admin"},
+		{name: "url wins over path", ann: map[string]string{p + "/path": "/admin", p + "/url": "/login"}, want: "https://app.example.com/login"},
+		{name: "invalid url falls back", ann: map[string]string{p + "/url": "foo"}, want: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{cfg: testConfig()}
+			route := map[string]interface{}{"namespace": "media", "name": "app", "annotations": tt.ann}
+			if got := c.rawRouteURL(route, "app.example.com"); got != tt.want {
+				t.Errorf("rawRouteURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// templateSettleDelay is how long the template files must stay unchanged
+// before a re-render is triggered.
+const templateSettleDelay = 250 * time.Millisecond
+
+// templateFromFiles reports whether the template is read from local files, so
+// it is watched and can fall back to its last good version.
+func (c *Controller) templateFromFiles() bool {
+	return c.cfg.TemplatePath != "" || c.cfg.TemplateDir != ""
+}
+
+// templateWatchPath returns the template file or directory in use.
+func (c *Controller) templateWatchPath() string {
+	if path := c.cfg.TemplatePath; path != "" {
+		return path
+	}
+	return c.cfg.TemplateDir
+}
+
+// watchTemplate signals reload whenever the --template-path file, or a *.tmpl
+// file of the --template-dir when isDir is set, changes. A file is watched via
+// its parent directory rather than directly: editors replace files on save and
+// Kubernetes updates ConfigMap volumes by swapping the "..data" symlink, both
+// of which would silently drop a watch on the file. Signals are coalesced, so
+// a burst of events within templateSettleDelay triggers a single re-render.
+func watchTemplate(ctx context.Context, path string, isDir bool, reload chan<- struct{}) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	dir := path
+	if !isDir {
+		dir = filepath.Dir(path)
+	}
+	if err := w.Add(dir); err != nil {
+		w.Close()
+		return fmt.Errorf("watch %q: %w", dir, err)
+	}
+
+	go func() {
+		defer w.Close()
+		// Saves often arrive as truncate-then-write; wait for the burst to
+		// settle so a half-written file is not rendered.
+		var settle <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if templateChanged(ev, path, isDir) {
+					settle = time.After(templateSettleDelay)
+				}
+			case <-settle:
+				settle = nil
+				ctrlLog().Info("custom template changed; re-rendering", "path", path)
+				select {
+				case reload <- struct{}{}:
+				default:
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				ctrlLog().Warn("template watcher error", "path", path, "error", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// templateChanged reports whether ev may have altered the template at path.
+func templateChanged(ev fsnotify.Event, path string, isDir bool) bool {
+	if ev.Op == fsnotify.Chmod {
+		return false
+	}
+	if filepath.Base(ev.Name) == "..data" {
+		return true
+	}
+	if isDir {
+		return filepath.Ext(ev.Name) == ".tmpl"
+	}
+	return filepath.Clean(ev.Name) == filepath.Clean(path)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"runtime"
+	"sync"
+)
+
+// collectItems runs the per-route include/extract work on a pool of
+// --workers goroutines. Results keep route order, and group icons are
+// resolved afterwards in that order (the first route of a group decides), so
+// the output is identical to a serial run regardless of scheduling. It also
+// counts the routes that yielded no items, by reason.
+func (c *Controller) collectItems(
+	routes []map[string]interface{},
+	nsMap map[string]namespaceMeta,
+	nsNames []string,
+	svcAnn map[string]map[string]string,
+) ([]ServiceItem, routeCounts) {
+	workers := c.cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(1, min(workers, len(routes)))
+	disabled := c.disabledGroups(nsMap)
+
+	results := make([][]ServiceItem, len(routes))
+	outcomes := make([]routeOutcome, len(routes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], outcomes[i] = c.processRoute(routes[i], nsMap, nsNames, svcAnn, disabled)
+			}
+		}()
+	}
+	for i := range routes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	groupIconCache := make(map[string]string)
+	var items []ServiceItem
+	var counts routeCounts
+	for i, batch := range results {
+		switch outcomes[i] {
+		case routeFiltered:
+			counts.filtered++
+		case routeNoHostname:
+			counts.noHostname++
+		}
+		for _, item := range batch {
+			if icon, seen := groupIconCache[item.Group]; seen {
+				item.GroupIcon = icon
+			} else {
+				groupIconCache[item.Group] = item.GroupIcon
+			}
+			items = append(items, item)
+		}
+	}
+	return items, counts
+}
+
+// routeOutcome is what processRoute did with a route.
+type routeOutcome int
+
+const (
+	routeIncluded routeOutcome = iota
+	// routeFiltered: rejected by the namespace, gateway, domain or
+	// enablement filters, left without hostnames by --exclude-hostnames, or
+	// in a disabled group.
+	routeFiltered
+	// routeNoHostname: passed the filters but has no usable link.
+	routeNoHostname
+)
+
+// routeCounts tallies the routes that yielded no items, by outcome.
+type routeCounts struct {
+	filtered   int
+	noHostname int
+}
+
+// processRoute returns the dashboard items for a single route, or nil when it
+// is filtered out (including by landing in a disabled group) or has no usable
+// link, along with which of these happened.
+func (c *Controller) processRoute(
+	route map[string]interface{},
+	nsMap map[string]namespaceMeta,
+	nsNames []string,
+	svcAnn map[string]map[string]string,
+	disabled map[string]bool,
+) ([]ServiceItem, routeOutcome) {
+	// Merge first, so an enabled annotation kept on the backend Service
+	// filters like one on the route.
+	if svcAnn != nil {
+		route = mergeBackendAnnotations(route, svcAnn)
+	}
+	route, ok := c.dropExcludedHostnames(route)
+	if !ok {
+		return nil, routeFiltered
+	}
+	if !c.shouldInclude(route, nsMap) {
+		return nil, routeFiltered
+	}
+	item, ok := c.extractItem(route, nsMap, nsNames)
+	if !ok {
+		return nil, routeNoHostname
+	}
+	if parent, _ := splitGroupPath(item.Group); disabled[item.Group] || disabled[parent] {
+		ctrlLog().Debug("excluding route: group disabled", "namespace", item.Namespace, "name", item.Route, "group", item.Group)
+		return nil, routeFiltered
+	}
+	return c.expandMultiURL(route, item), routeIncluded
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/mirceanton/homer-sync/internal/config"
+)
+
+func TestWorkersRenderIdentically(t *testing.T) {
+	p := config.AnnotationPrefix
+	var core, routes []runtime.Object
+	for n := 0; n < 5; n++ {
+		ns := fmt.Sprintf("team-%d", n)
+		core = append(core, testNamespace(ns, nil))
+		for r := 0; r < 20; r++ {
+			ann := map[string]string{
+				// Routes of every namespace share groups and disagree on
+				// their icon, so the first route of a group must decide.
+				p + "/group":      fmt.Sprintf("Group %d", r%3),
+				p + "/group-icon": fmt.Sprintf("fas fa-%d", n),
+				p + "/sort":       fmt.Sprint(r % 4),
+			}
+			name := fmt.Sprintf("app-%d", r)
+			routes = append(routes, testRoute(ns, name, ann, fmt.Sprintf("%s.%s.example.com", name, ns)))
+		}
+	}
+
+	render := func(workers int) map[string]string {
+		t.Helper()
+		cfg := testConfig()
+		cfg.NoHeader = true
+		cfg.Workers = workers
+		c, _ := newTestController(cfg, core, routes...)
+		out, err := c.Render(context.Background())
+		if err != nil {
+			t.Fatalf("render with %d workers: %v", workers, err)
+		}
+		return out
+	}
+
+	serial := render(1)
+	for _, workers := range []int{4, 16} {
+		parallel := render(workers)
+		if len(parallel) != len(serial) {
+			t.Fatalf("%d workers rendered %d outputs, want %d", workers, len(parallel), len(serial))
+		}
+		for name, want := range serial {
+			if got := parallel[name]; got != want {
+				t.Errorf("%d workers rendered %s differently:\n%s\nwant:\n%s", workers, name, got, want)
+			}
+		}
+	}
+}
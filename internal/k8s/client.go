@@ -2,31 +2,55 @@ package k8s
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	gatewayclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 )
 
-// Clients bundles the two API clients the controller needs.
+// Clients bundles the API clients the controller needs. Dynamic serves
+// optional sources without a typed clientset, such as OpenShift Routes.
+// Output writes the rendered config; it is Core unless an output cluster is
+// configured.
 type Clients struct {
 	Core    kubernetes.Interface
 	Gateway gatewayclient.Interface
+	Dynamic dynamic.Interface
+	Output  kubernetes.Interface
+}
+
+// Options tweaks how the API clients connect to the cluster.
+type Options struct {
+	// Kubeconfig and Context select the source cluster from a kubeconfig.
+	// An explicit kubeconfig is used even inside a pod; otherwise in-cluster
+	// config wins there and Context only applies outside a cluster.
+	Kubeconfig string
+	Context    string
+	// ProxyURL, when set, routes all API traffic through this proxy and takes
+	// precedence over HTTPS_PROXY/NO_PROXY from the environment.
+	ProxyURL string
+	// OutputKubeconfig and OutputContext, when either is set, select the
+	// cluster the rendered config is written to. An empty kubeconfig falls
+	// back to Kubeconfig, then the default loading rules ($KUBECONFIG,
+	// ~/.kube/config).
+	OutputKubeconfig string
+	OutputContext    string
 }
 
 // NewClients builds Kubernetes API clients, preferring in-cluster config and
-// falling back to the local kubeconfig.
-func NewClients() (*Clients, error) {
-	cfg, err := rest.InClusterConfig()
+// falling back to the local kubeconfig, unless a kubeconfig is given.
+func NewClients(opts Options) (*Clients, error) {
+	cfg, err := sourceConfig(opts)
 	if err != nil {
-		cfg, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-			clientcmd.NewDefaultClientConfigLoadingRules(),
-			&clientcmd.ConfigOverrides{},
-		).ClientConfig()
-		if err != nil {
-			return nil, fmt.Errorf("load kubernetes config: %w", err)
-		}
+		return nil, err
+	}
+
+	if err := applyProxy(cfg, opts.ProxyURL); err != nil {
+		return nil, err
 	}
 
 	core, err := kubernetes.NewForConfig(cfg)
@@ -39,5 +63,83 @@ func NewClients() (*Clients, error) {
 		return nil, fmt.Errorf("create gateway client: %w", err)
 	}
 
-	return &Clients{Core: core, Gateway: gw}, nil
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create dynamic client: %w", err)
+	}
+
+	out := kubernetes.Interface(core)
+	if opts.OutputKubeconfig != "" || opts.OutputContext != "" {
+		if out, err = newOutputClient(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Clients{Core: core, Gateway: gw, Dynamic: dyn, Output: out}, nil
+}
+
+// sourceConfig loads the config of the cluster routes are read from: the
+// explicit kubeconfig when one is given, else in-cluster config, else the
+// default loading rules, with Context selecting the kubeconfig context.
+func sourceConfig(opts Options) (*rest.Config, error) {
+	if opts.Kubeconfig == "" {
+		if cfg, err := rest.InClusterConfig(); err == nil {
+			return cfg, nil
+		}
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.Kubeconfig
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.Context},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubernetes config: %w", err)
+	}
+	return cfg, nil
+}
+
+// newOutputClient builds the core client for the output cluster from the
+// configured kubeconfig and context.
+func newOutputClient(opts Options) (kubernetes.Interface, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.OutputKubeconfig
+	if rules.ExplicitPath == "" {
+		rules.ExplicitPath = opts.Kubeconfig
+	}
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.OutputContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load output kubernetes config: %w", err)
+	}
+
+	if err := applyProxy(cfg, opts.ProxyURL); err != nil {
+		return nil, err
+	}
+
+	out, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create output client: %w", err)
+	}
+	return out, nil
+}
+
+// applyProxy sets cfg.Proxy from an explicit proxy URL, or from HTTPS_PROXY /
+// NO_PROXY when none is given. A proxy already set by the kubeconfig
+// (proxy-url) is only replaced by an explicit URL.
+func applyProxy(cfg *rest.Config, proxyURL string) error {
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("parse api proxy url %q: %w", proxyURL, err)
+		}
+		cfg.Proxy = http.ProxyURL(u)
+		return nil
+	}
+	if cfg.Proxy == nil {
+		cfg.Proxy = http.ProxyFromEnvironment
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyProxy(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com:6443/api", nil)
+	kubeconfigProxy, _ := url.Parse("http://kubeconfig-proxy:3128")
+	fromKubeconfig := func(*http.Request) (*url.URL, error) { return kubeconfigProxy, nil }
+
+	tests := []struct {
+		name     string
+		existing func(*http.Request) (*url.URL, error)
+		proxyURL string
+		want     string // proxy chosen for req; "" expects http.ProxyFromEnvironment
+		wantErr  bool
+	}{
+		{name: "explicit", proxyURL: "http://proxy.example.com:8080", want: "http://proxy.example.com:8080"},
+		{name: "explicit replaces kubeconfig", existing: fromKubeconfig, proxyURL: "http://proxy.example.com:8080", want: "http://proxy.example.com:8080"},
+		{name: "invalid", proxyURL: "http://[::1", wantErr: true},
+		{name: "unset keeps kubeconfig", existing: fromKubeconfig, want: "http://kubeconfig-proxy:3128"},
+		{name: "unset falls back to the environment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &rest.Config{Proxy: tt.existing}
+			err := applyProxy(cfg, tt.proxyURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyProxy(%q) error = %v, wantErr %v", tt.proxyURL, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Proxy == nil {
+				t.Fatal("applyProxy left cfg.Proxy unset")
+			}
+			if tt.want == "" {
+				if reflect.ValueOf(cfg.Proxy).Pointer() != reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+					t.Error("cfg.Proxy is not http.ProxyFromEnvironment")
+				}
+				return
+			}
+			got, err := cfg.Proxy(req)
+			if err != nil || got == nil || got.String() != tt.want {
+				t.Errorf("proxy for %s = %v, %v; want %s", req.URL, got, err, tt.want)
+			}
+		})
+	}
+}